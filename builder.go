@@ -0,0 +1,106 @@
+/*
+	InfluxDB client
+	(c) Copyright David Thorpe 2017
+	All Rights Reserved
+
+	For Licensing and Usage information, please see LICENSE file
+*/
+
+package influxdb
+
+import (
+	"sync"
+	"time"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// PointBuilder builds points, re-using the tag and field maps between
+// points to reduce allocations when writing at a high rate. The point
+// returned by Build is owned by the builder: it must not be retained or
+// modified after Reset or Release is called, so it should be written
+// (or copied) before the next point is built
+type PointBuilder struct {
+	point Point
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// GLOBALS
+
+var (
+	pointBuilderPool = sync.Pool{
+		New: func() interface{} {
+			return &PointBuilder{Point{
+				Tags:   make(map[string]string),
+				Fields: make(map[string]interface{}),
+			}}
+		},
+	}
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTRUCTOR
+
+// NewPointBuilder returns a builder for points in a measurement, from a
+// pool of builders. Call Release when the builder is no longer required
+func NewPointBuilder(measurement string) *PointBuilder {
+	this := pointBuilderPool.Get().(*PointBuilder)
+	this.Reset()
+	this.point.Measurement = measurement
+	return this
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Reset clears the tags, fields and timestamp, keeping the measurement
+// so that the builder can be used for the next point
+func (this *PointBuilder) Reset() *PointBuilder {
+	for k := range this.point.Tags {
+		delete(this.point.Tags, k)
+	}
+	for k := range this.point.Fields {
+		delete(this.point.Fields, k)
+	}
+	this.point.FieldOrder = this.point.FieldOrder[:0]
+	this.point.Time = time.Time{}
+	this.point.TimeUnixNano = 0
+	return this
+}
+
+// Tag sets a tag value
+func (this *PointBuilder) Tag(key, value string) *PointBuilder {
+	this.point.Tags[key] = value
+	return this
+}
+
+// Field sets a field value. Fields are recorded in the order they are
+// first set, for encoders which preserve field order
+func (this *PointBuilder) Field(key string, value interface{}) *PointBuilder {
+	if _, exists := this.point.Fields[key]; exists == false {
+		this.point.FieldOrder = append(this.point.FieldOrder, key)
+	}
+	this.point.Fields[key] = value
+	return this
+}
+
+// Time sets the timestamp for the point
+func (this *PointBuilder) Time(value time.Time) *PointBuilder {
+	this.point.Time = value
+	return this
+}
+
+// Build returns the point, which is only valid until the next call to
+// Reset or Release
+func (this *PointBuilder) Build() *Point {
+	return &this.point
+}
+
+// Release returns the builder to the pool. Neither the builder nor any
+// point built with it may be used afterwards
+func (this *PointBuilder) Release() {
+	this.Reset()
+	this.point.Measurement = ""
+	pointBuilderPool.Put(this)
+}
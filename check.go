@@ -0,0 +1,70 @@
+/*
+	InfluxDB client
+	(c) Copyright David Thorpe 2017
+	All Rights Reserved
+
+	For Licensing and Usage information, please see LICENSE file
+*/
+
+package influxdb
+
+import (
+	"fmt"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// CheckTagComparisons returns a warning for each comparison in the WHERE
+// clause of a SELECT query which compares a tag with a number or boolean.
+// Tag values are always strings, so these comparisons never match and
+// should compare with a string instead. The tag keys of each measurement
+// selected from are returned by tagKeys, usually from SHOW TAG KEYS.
+// Measurements selected by name or regular expression with From or
+// FromRegex are not checked. Returns any error from tagKeys
+func CheckTagComparisons(query Query, tagKeys func(*Measurement) ([]string, error)) ([]string, error) {
+	q, ok := query.(*q_Select)
+	if ok == false || tagKeys == nil || len(q.where) == 0 {
+		return nil, nil
+	}
+
+	// Collect the tag keys of the measurements
+	tags := make(map[string]bool)
+	for _, m := range q.measurement {
+		if keys, err := tagKeys(m); err != nil {
+			return nil, err
+		} else {
+			for _, key := range keys {
+				tags[key] = true
+			}
+		}
+	}
+
+	// Check the comparisons
+	warnings := make([]string, 0)
+	for _, predicate := range q.where {
+		warnings = checkTagComparisons(predicate, tags, warnings)
+	}
+	return warnings, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// checkTagComparisons appends warnings for comparisons of tags with values
+// which are not strings in a predicate
+func checkTagComparisons(predicate Predicate, tags map[string]bool, warnings []string) []string {
+	switch p := predicate.(type) {
+	case *p_Compare:
+		if _, ok := p.value.(string); ok == false && tags[p.name] {
+			warnings = append(warnings, fmt.Sprintf("%v: tag %v is a string, compare with %v", p.String(), Quote(p.name), literal(literal(p.value))))
+		}
+	case *p_Logical:
+		for _, value := range p.values {
+			warnings = checkTagComparisons(value, tags, warnings)
+		}
+	case *p_Not:
+		warnings = checkTagComparisons(p.value, tags, warnings)
+	}
+	return warnings
+}
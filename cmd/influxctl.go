@@ -3,7 +3,6 @@ package main
 
 import (
 	"errors"
-	"fmt"
 	"os"
 
 	// frameworks
@@ -38,33 +37,12 @@ var (
 		"Measurements":   influxctl.ListMeasurements,
 		"Query":          influxctl.Query,
 		"Import":         influxctl.Import,
+		"Write":          influxctl.Write,
 	}
 )
 
 ////////////////////////////////////////////////////////////////////////////////
 
-func GetOneArg(app *gopi.AppInstance, param1 string) (string, error) {
-	if args := app.AppFlags.Args(); len(args) < 2 {
-		return "", fmt.Errorf("Missing \"%v\" command-line argument", param1)
-	} else if len(args) > 2 {
-		return "", fmt.Errorf("Too many command-line arguments")
-	} else {
-		return args[1], nil
-	}
-}
-
-func GetPolicyValue(app *gopi.AppInstance) (*influxdb.RetentionPolicy, error) {
-	return &influxdb.RetentionPolicy{}, nil
-}
-
-func GetMeasurement(arg string) *influxdb.Measurement {
-	return &influxdb.Measurement{
-		Name: arg,
-	}
-}
-
-////////////////////////////////////////////////////////////////////////////////
-
 func MainTask(app *gopi.AppInstance, done chan<- struct{}) error {
 	// Call command
 	if args := app.AppFlags.Args(); len(args) < 1 {
@@ -90,8 +68,18 @@ func main() {
 	// Configuration
 	config := gopi.NewAppConfig(MODULE_NAME)
 	config.AppFlags.FlagString("db", "", "Database name")
+	config.AppFlags.FlagString("db-override", "", "Database name for Query, without changing -db")
 	config.AppFlags.FlagUint("limit", 1000, "Row limit")
 	config.AppFlags.FlagUint("offset", 0, "Row offset")
+	config.AppFlags.FlagString("since", "", "Start time (RFC3339, -duration or epoch seconds)")
+	config.AppFlags.FlagString("until", "", "End time (RFC3339, -duration or epoch seconds)")
+	config.AppFlags.FlagString("format", "ascii", "Output format (ascii, json)")
+	config.AppFlags.FlagBool("pretty", false, "Indent JSON output")
+	config.AppFlags.FlagString("timeformat", "", "Time format (Go layout)")
+	config.AppFlags.FlagString("tz", "", "Time zone for times (for example, Local or Europe/Berlin)")
+	config.AppFlags.FlagString("fields", "", "Fields for Write (k=v,...)")
+	config.AppFlags.FlagString("field-types", "", "Field types for Write, overriding inference (k=float,...)")
+	config.AppFlags.FlagString("tags", "", "Tags for Write (k=v,...)")
 
 	// Run Command-Line Tool
 	os.Exit(gopi.CommandLineTool(config, MainTask))
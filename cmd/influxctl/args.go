@@ -0,0 +1,31 @@
+package influxctl
+
+import (
+	"fmt"
+
+	// frameworks
+	gopi "github.com/djthorpe/gopi"
+	"github.com/djthorpe/influxdb"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+
+func GetOneArg(app *gopi.AppInstance, param1 string) (string, error) {
+	if args := app.AppFlags.Args(); len(args) < 2 {
+		return "", fmt.Errorf("Missing \"%v\" command-line argument", param1)
+	} else if len(args) > 2 {
+		return "", fmt.Errorf("Too many command-line arguments")
+	} else {
+		return args[1], nil
+	}
+}
+
+func GetPolicyValue(app *gopi.AppInstance) (*influxdb.RetentionPolicy, error) {
+	return &influxdb.RetentionPolicy{}, nil
+}
+
+func GetMeasurement(arg string) *influxdb.Measurement {
+	return &influxdb.Measurement{
+		Name: arg,
+	}
+}
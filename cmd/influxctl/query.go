@@ -4,7 +4,11 @@ import (
 
 	// frameworks
 	"errors"
+	"fmt"
+	"io"
 	"os"
+	"strings"
+	"time"
 
 	gopi "github.com/djthorpe/gopi"
 	"github.com/djthorpe/influxdb"
@@ -13,27 +17,146 @@ import (
 
 ////////////////////////////////////////////////////////////////////////////////
 
+var (
+	// precisionUnits maps query precision to the unit of returned times
+	precisionUnits = map[string]time.Duration{
+		influxdb.PRECISION_NANO:   time.Nanosecond,
+		influxdb.PRECISION_MICRO:  time.Microsecond,
+		influxdb.PRECISION_MICRO2: time.Microsecond,
+		influxdb.PRECISION_MILLI:  time.Millisecond,
+		influxdb.PRECISION_SECOND: time.Second,
+		influxdb.PRECISION_MINUTE: time.Minute,
+		influxdb.PRECISION_HOUR:   time.Hour,
+	}
+)
+
+////////////////////////////////////////////////////////////////////////////////
+
+// queryDatabase returns the database for a query and whether it should be
+// set as the current database. The -db-override flag takes precedence
+// over -db and does not change the current database, so that -db remains
+// the database for any other commands
+func queryDatabase(db, override string) (string, bool, error) {
+	if override != "" {
+		return override, false, nil
+	} else if db != "" {
+		return db, true, nil
+	} else {
+		return "", false, errors.New("-db or -db-override flag required")
+	}
+}
+
+// isStatement returns true if the Query argument is one or more InfluxQL
+// statements rather than a measurement name
+func isStatement(arg string) bool {
+	upper := strings.ToUpper(strings.TrimSpace(arg))
+	return strings.Contains(arg, ";") || strings.HasPrefix(upper, "SELECT ") || strings.HasPrefix(upper, "SHOW ")
+}
+
+// renderResults writes the results, with a header showing the statement
+// number before the results of each statement when there is more than one
+// statement, and a blank line between the results of each statement
+func renderResults(r influxdb.Results, w io.Writer, renderer tablewriter.Renderer, format string, pretty bool) error {
+	multi := false
+	for _, dataset := range r {
+		if dataset.Result != r[0].Result {
+			multi = true
+		}
+	}
+	for i, dataset := range r {
+		if multi && (i == 0 || dataset.Result != r[i-1].Result) {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			if format != "json" {
+				fmt.Fprintf(w, "Statement %v\n", dataset.Result+1)
+			}
+		}
+		if format == "json" {
+			if err := renderer.RenderJSON(dataset, w, pretty); err != nil {
+				return err
+			}
+		} else if err := renderer.RenderASCII(dataset, w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
 func Query(client influxdb.Client, app *gopi.AppInstance) error {
 	// Get flags
 	db, _ := app.AppFlags.GetString("db")
+	override, _ := app.AppFlags.GetString("db-override")
 	offset, _ := app.AppFlags.GetUint("offset")
 	limit, _ := app.AppFlags.GetUint("limit")
+	since, _ := app.AppFlags.GetString("since")
+	until, _ := app.AppFlags.GetString("until")
+	format, _ := app.AppFlags.GetString("format")
+	pretty, _ := app.AppFlags.GetBool("pretty")
+	timeformat, _ := app.AppFlags.GetString("timeformat")
+	tz, _ := app.AppFlags.GetString("tz")
+
+	// Output format
+	if format != "ascii" && format != "json" {
+		return fmt.Errorf("Invalid -format value: %v", format)
+	} else if pretty && format != "json" {
+		app.Logger.Warn("-pretty is ignored for -format %v", format)
+	}
+
+	// Time formatting
+	renderer := tablewriter.Renderer{TimeFormat: timeformat, EpochUnit: precisionUnits[client.Precision()]}
+	if tz != "" {
+		if location, err := time.LoadLocation(tz); err != nil {
+			return fmt.Errorf("Invalid -tz value: %v", tz)
+		} else {
+			renderer.TimeZone = location
+		}
+	}
 
-	if db == "" {
-		return errors.New("-db flag required")
-	} else if err := client.SetDatabase(db); err != nil {
+	// Time bounds
+	where := make([]influxdb.Predicate, 0, 2)
+	if since != "" {
+		if t, err := parseTimeFlag(since); err != nil {
+			return err
+		} else {
+			where = append(where, influxdb.GreaterThan("time", t))
+		}
+	}
+	if until != "" {
+		if t, err := parseTimeFlag(until); err != nil {
+			return err
+		} else {
+			where = append(where, influxdb.LessThan("time", t))
+		}
+	}
+
+	// Database
+	database, set, err := queryDatabase(db, override)
+	if err != nil {
 		return err
-	} else if measurement, err := GetOneArg(app, "Measurement"); err != nil {
+	} else if set {
+		if err := client.SetDatabase(database); err != nil {
+			return err
+		}
+	}
+
+	if measurement, err := GetOneArg(app, "Measurement"); err != nil {
 		return err
 	} else {
-		q := influxdb.Select(GetMeasurement(measurement)).OffsetLimit(offset, limit)
-		if r, err := client.Do(q); err != nil {
+		// Statements are run as is, otherwise select from the measurement
+		q := influxdb.Select(GetMeasurement(measurement)).Filter(where...).OffsetLimit(offset, limit)
+		if isStatement(measurement) {
+			if len(where) > 0 {
+				app.Logger.Warn("-since and -until are ignored for statements")
+			}
+			q = influxdb.Raw(measurement)
+		}
+		if r, err := client.QueryOn(database, q); err != nil {
 			return err
 		} else {
-			for _, dataset := range r {
-				tablewriter.RenderASCII(dataset, os.Stdout)
-			}
-			return nil
+			return renderResults(r, os.Stdout, renderer, format, pretty)
 		}
 	}
 }
@@ -0,0 +1,71 @@
+package influxctl
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/djthorpe/influxdb"
+	"github.com/djthorpe/influxdb/tablewriter"
+)
+
+func TestQueryDatabase_001(t *testing.T) {
+	tests := []struct {
+		db, override, database string
+		set                    bool
+	}{
+		{"metrics", "", "metrics", true},
+		{"metrics", "events", "events", false},
+		{"", "events", "events", false},
+	}
+	for _, test := range tests {
+		if database, set, err := queryDatabase(test.db, test.override); err != nil {
+			t.Error(err)
+		} else if database != test.database || set != test.set {
+			t.Errorf("For -db=%v -db-override=%v, expected %v %v, got %v %v", test.db, test.override, test.database, test.set, database, set)
+		}
+	}
+	if _, _, err := queryDatabase("", ""); err == nil {
+		t.Error("Expected error when no database flag is set")
+	}
+}
+
+func TestRenderResults_001(t *testing.T) {
+	r := influxdb.Results{
+		&influxdb.Result{Result: 0, Name: "cpu", Columns: []string{"time", "value"}, Values: [][]interface{}{{"2018-01-01T00:00:00Z", json.Number("1")}}},
+		&influxdb.Result{Result: 1, Name: "mem", Columns: []string{"time", "free"}, Values: [][]interface{}{{"2018-01-01T00:00:00Z", json.Number("2")}}},
+	}
+	buf := new(bytes.Buffer)
+	if err := renderResults(r, buf, tablewriter.Renderer{}, "ascii", false); err != nil {
+		t.Error(err)
+	} else if out := buf.String(); strings.Count(out, "Statement ") != 2 {
+		t.Error("Expected two statement headers, got", out)
+	} else if first, second := strings.Index(out, "Statement 1\n"), strings.Index(out, "\n\nStatement 2\n"); first != 0 || second < 0 {
+		t.Error("Expected headers separated by a blank line, got", out)
+	} else if strings.Contains(out[:second], "value") == false || strings.Contains(out[second:], "free") == false {
+		t.Error("Expected a table for each statement, got", out)
+	}
+
+	// A single statement has no header
+	buf.Reset()
+	if err := renderResults(r[:1], buf, tablewriter.Renderer{}, "ascii", false); err != nil {
+		t.Error(err)
+	} else if strings.Contains(buf.String(), "Statement") {
+		t.Error("Unexpected header, got", buf.String())
+	}
+}
+
+func TestIsStatement_001(t *testing.T) {
+	for arg, expected := range map[string]bool{
+		"cpu":                              false,
+		"db.autogen.cpu":                   false,
+		"select * from cpu":                true,
+		"SHOW MEASUREMENTS":                true,
+		"SELECT * FROM a; SELECT * FROM b": true,
+	} {
+		if isStatement(arg) != expected {
+			t.Errorf("For %q, expected %v", arg, expected)
+		}
+	}
+}
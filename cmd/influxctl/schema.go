@@ -81,7 +81,6 @@ func DropRetentionPolicy(client influxdb.Client, app *gopi.AppInstance) error {
 	} else {
 		return ListRetentionPolicies(client, app)
 	}
-	return nil
 }
 
 func ListRetentionPolicies(client influxdb.Client, app *gopi.AppInstance) error {
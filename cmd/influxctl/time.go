@@ -0,0 +1,46 @@
+package influxctl
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+
+var (
+	// timeNow returns the current time, and is replaced in tests
+	timeNow = time.Now
+
+	regexpEpoch = regexp.MustCompile("^\\d+$")
+)
+
+////////////////////////////////////////////////////////////////////////////////
+
+// parseTimeFlag returns a time from a command-line flag, which is one of
+// an RFC3339 timestamp (2018-01-02T15:04:05Z), a duration before now
+// (-1h30m) or a number of seconds since the epoch (1514905445)
+func parseTimeFlag(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, fmt.Errorf("Missing time value")
+	}
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+	if regexpEpoch.MatchString(value) {
+		if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return time.Unix(seconds, 0).UTC(), nil
+		}
+	}
+	if strings.HasPrefix(value, "-") {
+		if d, err := time.ParseDuration(value); err == nil {
+			return timeNow().Add(d), nil
+		}
+	} else if _, err := time.ParseDuration(value); err == nil {
+		return time.Time{}, fmt.Errorf("Ambiguous time %q: use -%v for a time before now", value, value)
+	}
+	return time.Time{}, fmt.Errorf("Invalid time %q: use an RFC3339 timestamp (2018-01-02T15:04:05Z), a duration before now (-1h) or seconds since the epoch", value)
+}
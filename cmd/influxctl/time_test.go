@@ -0,0 +1,33 @@
+package influxctl
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimeFlag_001(t *testing.T) {
+	now := time.Date(2018, 1, 2, 15, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	tests := map[string]time.Time{
+		"2018-01-02T15:04:05Z":        time.Date(2018, 1, 2, 15, 4, 5, 0, time.UTC),
+		"2018-01-02T15:04:05.5+01:00": time.Date(2018, 1, 2, 14, 4, 5, 500000000, time.UTC),
+		"-1h":                         now.Add(-time.Hour),
+		"-1h30m":                      now.Add(-90 * time.Minute),
+		"1514905445":                  time.Unix(1514905445, 0),
+		" 0 ":                         time.Unix(0, 0),
+	}
+	for value, expected := range tests {
+		if actual, err := parseTimeFlag(value); err != nil {
+			t.Errorf("For %q: %v", value, err)
+		} else if actual.Equal(expected) == false {
+			t.Errorf("For %q: expected %v, got %v", value, expected, actual)
+		}
+	}
+	for _, value := range []string{"", "1h", "yesterday", "2018-01-02", "-1x", "1.5"} {
+		if _, err := parseTimeFlag(value); err == nil {
+			t.Errorf("For %q: expected error", value)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package influxctl
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	// frameworks
+	gopi "github.com/djthorpe/gopi"
+	"github.com/djthorpe/influxdb"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Write writes a single point to a measurement, with the fields from the
+// -fields flag and the tags from the -tags flag. Field types are inferred
+// from the values unless they are set with the -field-types flag. The
+// point is written without a timestamp, so the server assigns one
+func Write(client influxdb.Client, app *gopi.AppInstance) error {
+	// Get flags
+	db, _ := app.AppFlags.GetString("db")
+	fields, _ := app.AppFlags.GetString("fields")
+	types, _ := app.AppFlags.GetString("field-types")
+	tags, _ := app.AppFlags.GetString("tags")
+
+	// Select database, retrieve measurement name
+	if db == "" {
+		return errors.New("-db flag required")
+	} else if err := client.SetDatabase(db); err != nil {
+		return err
+	} else if measurement, err := GetOneArg(app, "Measurement"); err != nil {
+		return err
+	} else if field_values, err := parseFields(fields, types); err != nil {
+		return err
+	} else if tag_values, err := parsePairs(tags); err != nil {
+		return err
+	} else {
+		return client.WritePoint(&influxdb.Point{
+			Measurement: measurement,
+			Tags:        tag_values,
+			Fields:      field_values,
+		})
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// parseFields returns field values from a comma-separated list of k=v
+// pairs. The type of each field is taken from types, a comma-separated
+// list of k=type pairs where the type is one of float, integer, unsigned,
+// boolean or string, or otherwise inferred from the value: integers, then
+// floats, then booleans, with anything else a string. Returns an error
+// for a type which is not known, a type for a field which is not set, or
+// a value which cannot be converted to its type
+func parseFields(fields, types string) (map[string]interface{}, error) {
+	values, err := parsePairs(fields)
+	if err != nil {
+		return nil, err
+	} else if len(values) == 0 {
+		return nil, errors.New("-fields flag required")
+	}
+	pinned, err := parsePairs(types)
+	if err != nil {
+		return nil, err
+	}
+	for key := range pinned {
+		if _, exists := values[key]; exists == false {
+			return nil, fmt.Errorf("Field type for %q, which is not in -fields", key)
+		}
+	}
+	result := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		if field_type, exists := pinned[key]; exists == false {
+			result[key] = inferValue(value)
+		} else if result[key], err = convertValue(value, field_type); err != nil {
+			return nil, fmt.Errorf("Field %q: %v", key, err)
+		}
+	}
+	return result, nil
+}
+
+// parsePairs returns the pairs in a comma-separated list of k=v pairs
+func parsePairs(value string) (map[string]string, error) {
+	pairs := make(map[string]string)
+	if strings.TrimSpace(value) == "" {
+		return pairs, nil
+	}
+	for _, pair := range strings.Split(value, ",") {
+		if kv := strings.SplitN(pair, "=", 2); len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, fmt.Errorf("Invalid key=value pair %q", pair)
+		} else if _, exists := pairs[strings.TrimSpace(kv[0])]; exists {
+			return nil, fmt.Errorf("Duplicate key %q", strings.TrimSpace(kv[0]))
+		} else {
+			pairs[strings.TrimSpace(kv[0])] = kv[1]
+		}
+	}
+	return pairs, nil
+}
+
+// inferValue returns a field value as an integer, float or boolean when
+// it can be parsed as one, or otherwise as a string
+func inferValue(value string) interface{} {
+	if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return v
+	} else if v, err := strconv.ParseFloat(value, 64); err == nil {
+		return v
+	} else if v, err := strconv.ParseBool(value); err == nil {
+		return v
+	} else {
+		return value
+	}
+}
+
+// convertValue returns a field value converted to a field type
+func convertValue(value, field_type string) (interface{}, error) {
+	switch field_type {
+	case "float":
+		return strconv.ParseFloat(value, 64)
+	case "integer":
+		return strconv.ParseInt(value, 10, 64)
+	case "unsigned":
+		return strconv.ParseUint(value, 10, 64)
+	case "boolean":
+		return strconv.ParseBool(value)
+	case "string":
+		return value, nil
+	default:
+		return nil, fmt.Errorf("Invalid field type %q (use float, integer, unsigned, boolean or string)", field_type)
+	}
+}
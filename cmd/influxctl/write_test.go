@@ -0,0 +1,34 @@
+package influxctl
+
+import (
+	"testing"
+)
+
+func TestParseFields_001(t *testing.T) {
+	// Types are inferred from values
+	if fields, err := parseFields("count=1,load=1.5,up=true,host=a", ""); err != nil {
+		t.Error(err)
+	} else if fields["count"] != int64(1) || fields["load"] != 1.5 || fields["up"] != true || fields["host"] != "a" {
+		t.Error("Unexpected fields", fields)
+	}
+
+	// Explicit types override inference
+	if fields, err := parseFields("count=1,load=1,up=true,id=42", "load=float,up=string,id=unsigned"); err != nil {
+		t.Error(err)
+	} else if fields["count"] != int64(1) || fields["load"] != float64(1) || fields["up"] != "true" || fields["id"] != uint64(42) {
+		t.Error("Unexpected fields", fields)
+	}
+
+	for _, test := range [][2]string{
+		{"", ""},
+		{"load=x", "load=float"},
+		{"load=1", "load=double"},
+		{"load=1", "other=float"},
+		{"load", ""},
+		{"load=1,load=2", ""},
+	} {
+		if _, err := parseFields(test[0], test[1]); err == nil {
+			t.Errorf("For %q and %q: expected error", test[0], test[1])
+		}
+	}
+}
@@ -8,7 +8,9 @@
 package influxdb
 
 import (
+	"context"
 	"errors"
+	"io"
 	"time"
 
 	gopi "github.com/djthorpe/gopi"
@@ -37,6 +39,33 @@ const (
 	PRECISION_DEFAULT string = PRECISION_MILLI
 )
 
+const (
+	// ConfirmDropAllSeries confirms dropping all series in a measurement
+	ConfirmDropAllSeries Confirmation = "drop all series"
+
+	// ConfirmRenameTag confirms rewriting the data in a measurement to
+	// rename a tag
+	ConfirmRenameTag Confirmation = "rename tag"
+)
+
+const (
+	// ErrorKind is the kind of an error returned by the server, from
+	// ClassifyError
+	ErrorUnknown ErrorKind = iota
+	ErrorFieldTypeConflict
+	ErrorRetentionPolicyNotFound
+	ErrorDatabaseNotFound
+	ErrorAuthFailure
+)
+
+const (
+	// QueuePolicy is the behaviour of a BufferedWriter when the queue
+	// is full: QueueBlock waits for space and QueueReject returns
+	// ErrQueueFull
+	QueueBlock QueuePolicy = iota
+	QueueReject
+)
+
 ////////////////////////////////////////////////////////////////////////////////
 // GLOBAL VARIABLES
 
@@ -61,11 +90,72 @@ var (
 
 	// ErrNotSupported is returned if a feature is not yet supported
 	ErrNotSupported = errors.New("Not supported")
+
+	// ErrUnhealthy is returned when the server reports it is not healthy
+	ErrUnhealthy = errors.New("Server is unhealthy")
+
+	// ErrDryRun is returned (wrapped in a DryRunError) by destructive
+	// operations when dry run is enabled
+	ErrDryRun = errors.New("Dry run")
+
+	// ErrPartialResult is returned with the results of a query when the
+	// server indicates that a series has been truncated
+	ErrPartialResult = errors.New("Partial result from server")
+
+	// ErrFieldTypeConflict is returned when a field value does not match
+	// the type of the existing field in a measurement
+	ErrFieldTypeConflict = errors.New("Field type conflict")
+
+	// ErrDatabaseNotFound is returned when a named database does not exist
+	ErrDatabaseNotFound = errors.New("Database not found")
+
+	// ErrConfirmationRequired is returned by destructive operations which
+	// are called without the required confirmation
+	ErrConfirmationRequired = errors.New("Confirmation required")
+
+	// ErrWriterClosed is returned when writing to a closed writer
+	ErrWriterClosed = errors.New("Writer is closed")
+
+	// ErrPayloadTooLarge is returned when a write is larger than the
+	// maximum request body size of the server
+	ErrPayloadTooLarge = errors.New("Payload too large, reduce the batch size")
+
+	// ErrReadOnly is returned when writing to a server which is read-only
+	ErrReadOnly = errors.New("Server is read-only")
+
+	// ErrQueueFull is returned when writing to a writer whose queue of
+	// points is full
+	ErrQueueFull = errors.New("Queue is full")
 )
 
 ////////////////////////////////////////////////////////////////////////////////
 // TYPES
 
+// ErrorKind classifies an error returned by the server
+type ErrorKind uint
+
+// QueuePolicy defines what a BufferedWriter does when the queue is full
+type QueuePolicy uint
+
+// Confirmation is passed to destructive operations to confirm that
+// the operation is intended
+type Confirmation string
+
+// DryRunError is returned by destructive operations when dry run is enabled,
+// and contains the statement which would have been executed
+type DryRunError struct {
+	Statement string
+}
+
+// BuildInfo defines the build of the server, from the diagnostics. The
+// build time is as reported by the server, and is often empty
+type BuildInfo struct {
+	Version   string
+	Branch    string
+	Commit    string
+	BuildTime string
+}
+
 // RetentionPolicy defines a period of time to store measurement data for
 type RetentionPolicy struct {
 	Duration           time.Duration
@@ -74,6 +164,67 @@ type RetentionPolicy struct {
 	Default            bool
 }
 
+// RunningQuery defines a query which is currently executing on the server
+type RunningQuery struct {
+	QID      uint64
+	Query    string
+	Database string
+	Duration time.Duration
+}
+
+// Shard defines a shard, which stores the data for a retention
+// policy over a period of time
+type Shard struct {
+	ID         uint64
+	Database   string
+	Policy     string
+	ShardGroup uint64
+	Start      time.Time
+	End        time.Time
+	Expiry     time.Time
+}
+
+// ShardGroup defines a shard group, which contains the shards for a
+// retention policy over a period of time
+type ShardGroup struct {
+	ID       uint64
+	Database string
+	Policy   string
+	Start    time.Time
+	End      time.Time
+	Expiry   time.Time
+}
+
+// Summary describes the data in a measurement: the timestamps of the
+// first and last points, the number of points and series, and the field
+// keys mapped to their types
+type Summary struct {
+	Measurement string
+	First       time.Time
+	Last        time.Time
+	Points      uint64
+	Series      uint64
+	Fields      map[string]string
+}
+
+// Subscription defines a subscription which forwards writes to a
+// retention policy to a set of destinations. The mode is "ALL" or "ANY"
+type Subscription struct {
+	Database        string
+	RetentionPolicy string
+	Name            string
+	Mode            string
+	Destinations    []string
+}
+
+// Grant defines a privilege granted to a user on a database, which is
+// one of "READ", "WRITE" or "ALL PRIVILEGES". An empty database means
+// all databases, which is implied for admin users
+type Grant struct {
+	Database  string
+	Privilege string
+}
+
 // Result reflects the influxdb model.Row structure but which defines a number
 // of additional methods
 type Result struct {
@@ -84,6 +235,16 @@ type Result struct {
 	Columns []string
 	Values  [][]interface{}
 	Partial bool
+
+	// types caches the inferred column types
+	types []string
+
+	// precision is the epoch precision of integer times, or empty for
+	// RFC3339 times
+	precision string
+
+	// fields are the field types used by ToLineProtocol
+	fields map[string]string
 }
 
 // Results is a set of results (usually one, but may be more if more than one measure
@@ -100,6 +261,28 @@ type Measurement struct {
 	Policy   string
 }
 
+// Point defines a single point for writing. The measurement may be
+// qualified with a retention policy and database, in which case the
+// point is written to that target
+type Point struct {
+	Measurement string
+	Tags        map[string]string
+	Fields      map[string]interface{}
+
+	// Time is the timestamp of the point. When Time is zero and
+	// TimeUnixNano is not set, the point is written without a timestamp
+	// and the server assigns its local time
+	Time time.Time
+
+	// FieldOrder is the order in which fields are written when the line
+	// encoder preserves field order, and is otherwise ignored
+	FieldOrder []string
+
+	// TimeUnixNano when not zero is the timestamp in nanoseconds since
+	// the epoch, which is written as is instead of Time
+	TimeUnixNano int64
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // INTERFACES
 
@@ -108,11 +291,21 @@ type Client interface {
 	gopi.Driver
 
 	// Get and set parameters
+	Addr() string
 	Version() string
+	VersionAtLeast(major, minor int) bool
+	BuildInfo() (*BuildInfo, error)
+	HealthCheck(ctx context.Context) error
+	WaitReady(ctx context.Context, interval time.Duration) error
 	Database() string
 	SetDatabase(value string) error
 	Precision() string
 	SetPrecision(value string) error
+	DryRun() bool
+	SetDryRun(value bool)
+	WithDryRun(value bool) Client
+	WithCache(ttl time.Duration) Client
+	PublishExpvar(prefix string) error
 
 	// Convenience methods for database and retention policy
 	CreateDatabase(name string, policy *RetentionPolicy) error
@@ -120,15 +313,97 @@ type Client interface {
 	DropDatabase(name string) error
 	DropRetentionPolicy(name string) error
 	RetentionPolicies() (map[string]*RetentionPolicy, error)
+	DefaultRetentionPolicy(db string) (string, error)
+	SetupDownsampling(db, rawRP, aggRP string, every time.Duration) error
+
+	// Storage diagnostics
+	ShowShards() (map[string][]*Shard, error)
+	ShowShardGroups() ([]*ShardGroup, error)
+	DropShard(id int) error
+	EstimatePointCount(measurement string) (int64, error)
+	CreateSnapshot(db string) error
+
+	// Subscriptions
+	ShowSubscriptions() ([]Subscription, error)
+
+	// Users and privileges
+	BootstrapAdmin(user, password string) error
+	ShowGrants(user string) ([]Grant, error)
+
+	// Manage running queries
+	ShowQueries() ([]*RunningQuery, error)
+	KillQuery(qid uint64) error
 
 	// Excute a query
 	Do(query Query) (Results, error)
+	QueryOn(database string, query Query) (Results, error)
+	QueryOrEmpty(query string) (*Result, error)
+	QueryWithMessages(query string) (*Result, []string, error)
+	QueryIter(query string) (*RowIter, error)
+	QueryGrouped(query string) (map[string]*Result, error)
+	ExplainQuery(query string) (*Result, error)
+	CheckQuery(query Query) ([]string, error)
+	AnalyzeQuery(query string) (*Result, error)
+	QueryWithServerTimeout(query string, timeout time.Duration) (Results, error)
+	QueryConcurrent(queries []Query, maxParallel int) ([]Results, error)
+	Tail(measurement string, interval time.Duration, fn func(*Result) error) (func(), error)
+	ExportCursor(measurement string, pageSize int) (Cursor, error)
+	ExportCSV(query string, w io.Writer, chunkSize int) error
+	ExportSeriesByGroup(measurement string, seriesPerPage int, fn func(*Result) error) error
+	QueryCSV(query string) ([][]string, error)
+
+	// Aggregate a measurement field over a period up to now, returning
+	// ErrEmptyResponse when there is no matching data
+	Mean(measurement, field string, since time.Duration) (float64, error)
+	Max(measurement, field string, since time.Duration) (float64, error)
+	Min(measurement, field string, since time.Duration) (float64, error)
+	Sum(measurement, field string, since time.Duration) (float64, error)
+	Count(measurement, field string, since time.Duration) (float64, error)
+	Percentile(measurement, field string, p float64, since time.Duration) (float64, error)
+	LastValue(measurement, field string) (time.Time, Value, error)
+
+	// Copy data between measurements
+	CopyMeasurement(src, dst string, where ...Predicate) error
+	CopyDatabase(src, dst string, measurements []string) error
+	MigrateToPolicy(measurement, fromRP, toRP string) error
+	CopyRetentionPolicy(srcDB, rpName, dstDB string) error
+	RenameTag(measurement, oldKey, newKey string, confirm Confirmation) error
+
+	// Write points
+	WritePoint(point *Point) error
+	WriteBatch(points []*Point) error
+	WriteBatchContext(ctx context.Context, points []*Point) error
+	WriteVerified(point *Point, within time.Duration) error
+	WriteJSONStream(ctx context.Context, r io.Reader) (int, error)
+	ValidatePoint(point *Point) error
+	RefreshSchema(measurement string)
+
+	// Schema
+	GetMeasurements(database string) ([]string, error)
+	GetMeasurementsMatching(pattern string) ([]string, error)
+	DropMeasurementsMatching(pattern string) (int, error)
+	ShowFieldKeys(measurement string) (map[string]string, error)
+	ShowTagValues(measurement string, keys ...string) (map[string][]string, error)
+	GetMeasurementsWithTagKeys() (map[string][]string, error)
+	SeriesCardinalityExact(measurement string) (int, error)
+	DropAllSeriesInMeasurement(measurement string, confirm Confirmation) error
+	MeasurementSummary(measurement string) (*Summary, error)
 
 	// Return an empty dataset and write data
 	NewDataset(name string, tags, fields []string) (Dataset, error)
 	Write(Dataset) error
 }
 
+// Cursor reads a measurement a page at a time in time order. Next
+// returns the next page and true, or false when there are no more pages.
+// Position returns a token for the position after the last page read,
+// which can be passed to Seek on a new cursor to resume reading
+type Cursor interface {
+	Next() (*Result, bool, error)
+	Position() string
+	Seek(position string) error
+}
+
 // Dataset is an abstract set of data which is written or read
 // from the database
 type Dataset interface {
@@ -154,8 +429,26 @@ type Query interface {
 	RetentionPolicy(value *RetentionPolicy) Query
 	Default(value bool) Query
 	Measurement(values ...*Measurement) Query
+	From(names ...string) Query
+	FromRegex(pattern string) Query
+	FromPolicies(measurement string, policies ...string) Query
 	OffsetLimit(offset uint, limit uint) Query
+	SeriesOffsetLimit(offset uint, limit uint) Query
 	Filter(values ...Predicate) Query
+	Where(value Predicate) Query
+	Columns(values ...Predicate) Query
+	Into(value *Measurement) Query
+	GroupBy(values ...string) Query
+	GroupByTime(interval time.Duration) Query
+	GroupByTimeOffset(interval, offset time.Duration) Query
+
+	// Set the time zone for returned times and GROUP BY time boundaries,
+	// returning ErrBadParameter if the location is not known
+	TZ(location string) (Query, error)
+
+	// Select the distinct values of a field, returning ErrBadParameter
+	// if other columns have already been selected
+	Distinct(field string) (Query, error)
 
 	// Return the query as a string
 	String() string
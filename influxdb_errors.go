@@ -0,0 +1,71 @@
+/*
+	InfluxDB client
+	(c) Copyright David Thorpe 2017
+	All Rights Reserved
+
+	For Licensing and Usage information, please see LICENSE file
+*/
+
+package influxdb
+
+import (
+	"errors"
+	"strings"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// GLOBAL VARIABLES
+
+var (
+	// errorPatterns maps lower case substrings of server error messages to
+	// the kind of error, checked in order
+	errorPatterns = []struct {
+		pattern string
+		kind    ErrorKind
+	}{
+		{"field type conflict", ErrorFieldTypeConflict},
+		{"retention policy not found", ErrorRetentionPolicyNotFound},
+		{"database not found", ErrorDatabaseNotFound},
+		{"authorization failed", ErrorAuthFailure},
+		{"authentication failed", ErrorAuthFailure},
+		{"unable to parse authentication credentials", ErrorAuthFailure},
+		{"not authorized", ErrorAuthFailure},
+	}
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// ERRORS
+
+// Error returns the dry run error message, including the statement
+func (e *DryRunError) Error() string {
+	return ErrDryRun.Error() + ": " + e.Statement
+}
+
+// Unwrap returns ErrDryRun
+func (e *DryRunError) Unwrap() error {
+	return ErrDryRun
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CLASSIFY
+
+// ClassifyError returns the kind of an error returned by the server, from
+// the sentinel errors it wraps or by matching known server messages, such
+// as "partial write: field type conflict". Returns ErrorUnknown for nil
+// and unrecognised errors
+func ClassifyError(err error) ErrorKind {
+	if err == nil {
+		return ErrorUnknown
+	} else if errors.Is(err, ErrFieldTypeConflict) {
+		return ErrorFieldTypeConflict
+	} else if errors.Is(err, ErrDatabaseNotFound) {
+		return ErrorDatabaseNotFound
+	}
+	message := strings.ToLower(err.Error())
+	for _, pattern := range errorPatterns {
+		if strings.Contains(message, pattern.pattern) {
+			return pattern.kind
+		}
+	}
+	return ErrorUnknown
+}
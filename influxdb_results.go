@@ -11,9 +11,40 @@ package influxdb
 import (
 	"encoding/json"
 	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 )
 
+////////////////////////////////////////////////////////////////////////////////
+// GLOBALS
+
+const (
+	// typeSamples is the number of non-nil values sampled per column
+	// when inferring column types
+	typeSamples = 100
+)
+
+var (
+	regexpDurationUnit = regexp.MustCompile("^(\\d+)(ns|us|u|µs|µ|ms|s|m|h|d|w)")
+	durationUnits      = map[string]time.Duration{
+		"ns": time.Nanosecond,
+		"us": time.Microsecond,
+		"u":  time.Microsecond,
+		"µs": time.Microsecond,
+		"µ":  time.Microsecond,
+		"ms": time.Millisecond,
+		"s":  time.Second,
+		"m":  time.Minute,
+		"h":  time.Hour,
+		"d":  time.Hour * 24,
+		"w":  time.Hour * 24 * 7,
+	}
+)
+
 ////////////////////////////////////////////////////////////////////////////////
 // PUBLIC METHODS
 
@@ -32,45 +63,741 @@ func (r Results) Column(result int, series string, column string) ([]Value, erro
 	return nil, ErrBadParameter
 }
 
+// SortedTagKeys returns the tag keys for a series, sorted lexicographically
+func (r *Result) SortedTagKeys() []string {
+	return sortedKeys(r.Tags)
+}
+
+// SeriesKey returns the canonical series key for a series in the form
+// measurement,k1=v1,k2=v2 with tags sorted by key, escaped as for the line
+// protocol. The key can be used for grouping and comparing series
+func (r *Result) SeriesKey() string {
+	key := []string{escapeMeasurement(r.Name)}
+	for _, k := range r.SortedTagKeys() {
+		key = append(key, escapeTag(k)+"="+escapeTag(r.Tags[k]))
+	}
+	return strings.Join(key, ",")
+}
+
+// ColumnNames returns the column names with duplicates made unique, since
+// aliases can produce several columns with the same name. The first
+// column with a name keeps the name, and later columns are suffixed with
+// "_2", "_3" and so on, skipping any suffixed name which is already a
+// column name
+func (r *Result) ColumnNames() []string {
+	names := make([]string, len(r.Columns))
+	seen := make(map[string]bool, len(r.Columns))
+	for _, column := range r.Columns {
+		seen[column] = false
+	}
+	for i, column := range r.Columns {
+		if seen[column] == false {
+			names[i], seen[column] = column, true
+			continue
+		}
+		for n := 2; ; n++ {
+			name := column + "_" + strconv.Itoa(n)
+			if _, exists := seen[name]; exists == false {
+				names[i], seen[name] = name, true
+				break
+			}
+		}
+	}
+	return names
+}
+
+// ColumnIndex returns the index of a column from the names returned by
+// ColumnNames, so that a duplicate column can be found with its suffixed
+// name, or -1 if there is no such column
+func (r *Result) ColumnIndex(name string) int {
+	for i, column := range r.ColumnNames() {
+		if column == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// RowMap returns the values of a row keyed by the names returned by
+// ColumnNames, so that duplicate columns are not overwritten. Returns
+// nil if the row does not exist
+func (r *Result) RowMap(row int) map[string]interface{} {
+	if row < 0 || row >= len(r.Values) {
+		return nil
+	}
+	names := r.ColumnNames()
+	values := make(map[string]interface{}, len(names))
+	for i, value := range r.Values[row] {
+		if i < len(names) {
+			values[names[i]] = value
+		}
+	}
+	return values
+}
+
+// FlattenTags returns a copy of the result with the tags of a series,
+// sorted by key, prepended to each row as columns, for processing and
+// exporting the series of a GROUP BY query as a single flat result. The
+// copy has no tags, and the result is not modified
+func (r *Result) FlattenTags() *Result {
+	keys := r.SortedTagKeys()
+	flat := &Result{
+		Result:    r.Result,
+		Series:    r.Series,
+		Name:      r.Name,
+		Columns:   append(append(make([]string, 0, len(keys)+len(r.Columns)), keys...), r.Columns...),
+		Values:    make([][]interface{}, len(r.Values)),
+		Partial:   r.Partial,
+		precision: r.precision,
+		fields:    r.fields,
+	}
+	for i, row := range r.Values {
+		values := make([]interface{}, 0, len(keys)+len(row))
+		for _, key := range keys {
+			values = append(values, r.Tags[key])
+		}
+		flat.Values[i] = append(values, row...)
+	}
+	return flat
+}
+
+// ToLineProtocol returns each row of the result as a line of line
+// protocol, with the result name as the measurement, the tags of the
+// series as the tag set and the other columns as fields. The time column
+// is written as a timestamp in units of the precision, or nanoseconds
+// when the precision is empty. Numbers are written as floats, since the
+// server returns floats with whole values without a decimal point, unless
+// the field is an integer or unsigned field in the types set with
+// SetFieldTypes. Null values are omitted, so rows without field values
+// are skipped. Returns ErrBadParameter if the precision is not known
+func (r *Result) ToLineProtocol(precision string) ([]string, error) {
+	unit := time.Nanosecond
+	if precision != "" {
+		var exists bool
+		if unit, exists = durationUnits[precision]; exists == false {
+			return nil, fmt.Errorf("%w: precision %q", ErrBadParameter, precision)
+		}
+	}
+	names := r.ColumnNames()
+	time_col := r.ColumnIndex("time")
+	lines := make([]string, 0, len(r.Values))
+	for i, row := range r.Values {
+		point := &Point{Measurement: r.Name, Tags: r.Tags, Fields: make(map[string]interface{}, len(row))}
+		for j, value := range row {
+			if j >= len(names) || j == time_col || value == nil {
+				continue
+			} else if number, ok := value.(json.Number); ok == false {
+				point.Fields[names[j]] = value
+			} else if field_type := r.fields[names[j]]; field_type == "integer" {
+				if n, err := number.Int64(); err != nil {
+					return nil, ErrUnexpectedResponse
+				} else {
+					point.Fields[names[j]] = n
+				}
+			} else if field_type == "unsigned" {
+				if n, err := strconv.ParseUint(number.String(), 10, 64); err != nil {
+					return nil, ErrUnexpectedResponse
+				} else {
+					point.Fields[names[j]] = n
+				}
+			} else if f, err := number.Float64(); err != nil {
+				return nil, ErrUnexpectedResponse
+			} else {
+				point.Fields[names[j]] = f
+			}
+		}
+		if len(point.Fields) == 0 {
+			continue
+		}
+		line, err := (LineEncoder{Unsigned: true}).Encode(point)
+		if err != nil {
+			return nil, err
+		}
+		if time_col >= 0 {
+			if ts, err := r.Time(i, time_col); err != nil {
+				return nil, err
+			} else {
+				line += " " + strconv.FormatInt(ts.UnixNano()/int64(unit), 10)
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+// SetFieldTypes sets the types of fields written by ToLineProtocol, such
+// as the field keys returned by ShowFieldKeys for the measurement, so
+// that integer and unsigned fields keep their types
+func (r *Result) SetFieldTypes(types map[string]string) {
+	r.fields = types
+}
+
+// SetTimePrecision sets the epoch precision of integer times returned by
+// Time, such as "ms", for the results of a query made with a precision.
+// An empty precision, which is the default, parses times as RFC3339
+func (r *Result) SetTimePrecision(p string) {
+	r.precision = p
+}
+
+// Time returns the value in a row and column as a time. When a precision
+// has been set with SetTimePrecision, the value is an integer number of
+// units of the precision since the epoch, and otherwise is an RFC3339
+// time. Returns ErrBadParameter if the row or column does not exist or the
+// precision is not known, and ErrUnexpectedResponse if the value is not a
+// time
+func (r *Result) Time(row, col int) (time.Time, error) {
+	if row < 0 || row >= len(r.Values) || col < 0 || col >= len(r.Values[row]) {
+		return time.Time{}, ErrBadParameter
+	}
+	value := r.Values[row][col]
+	if r.precision == "" {
+		if t, ok := toTime(value); ok {
+			return t, nil
+		}
+		return time.Time{}, ErrUnexpectedResponse
+	}
+	unit, exists := durationUnits[r.precision]
+	if exists == false {
+		return time.Time{}, fmt.Errorf("%w: precision %q", ErrBadParameter, r.precision)
+	}
+	if number, ok := toNumber(value); ok == false {
+		return time.Time{}, ErrUnexpectedResponse
+	} else if n, err := strconv.ParseInt(number, 10, 64); err != nil {
+		return time.Time{}, ErrUnexpectedResponse
+	} else {
+		return time.Unix(0, n*int64(unit)).UTC(), nil
+	}
+}
+
+// Equal returns true if two results have the same name, tags, columns
+// and values, with columns and rows in the same order. Numeric values are
+// compared by value, so that json.Number("1") is equal to int64(1), and
+// times are compared as instants
+func (r *Result) Equal(other *Result) bool {
+	if r == nil || other == nil {
+		return r == other
+	} else if r.Name != other.Name || len(r.Tags) != len(other.Tags) {
+		return false
+	} else if len(r.Columns) != len(other.Columns) || len(r.Values) != len(other.Values) {
+		return false
+	}
+	for key, value := range r.Tags {
+		if other_value, exists := other.Tags[key]; exists == false || value != other_value {
+			return false
+		}
+	}
+	for i, column := range r.Columns {
+		if other.Columns[i] != column {
+			return false
+		}
+	}
+	for i, row := range r.Values {
+		if len(row) != len(other.Values[i]) {
+			return false
+		}
+		for j, value := range row {
+			if equalValue(value, other.Values[i][j]) == false {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// InferColumnTypes returns the type of each column, which is one of
+// "time", "float", "integer", "string" or "boolean", sampling the non-nil
+// values in the column, since the server response does not include column
+// types. Integer and float values in the same column are a float column,
+// and other mixed columns are string columns. A column with no non-nil
+// values has an empty type. The types are cached, so should only be
+// inferred once the values are complete
+func (r *Result) InferColumnTypes() []string {
+	if r.types != nil && len(r.types) == len(r.Columns) {
+		return r.types
+	}
+	types := make([]string, len(r.Columns))
+	for i := range r.Columns {
+		samples := 0
+		for _, row := range r.Values {
+			if i >= len(row) || row[i] == nil {
+				continue
+			}
+			types[i] = mergeColumnType(types[i], valueType(row[i]))
+			if samples++; samples >= typeSamples {
+				break
+			}
+		}
+	}
+	r.types = types
+	return types
+}
+
+// ParseFieldKeys returns a map of field keys to field types from a server
+// response to SHOW FIELD KEYS
+func (r *Result) ParseFieldKeys() (map[string]string, error) {
+	key_col, type_col := r.columnindex("fieldKey"), r.columnindex("fieldType")
+	if key_col < 0 || type_col < 0 {
+		return nil, ErrUnexpectedResponse
+	}
+	fields := make(map[string]string, len(r.Values))
+	for _, row := range r.Values {
+		if len(row) != len(r.Columns) {
+			return nil, ErrUnexpectedResponse
+		} else if key, ok := row[key_col].(string); ok == false {
+			return nil, ErrUnexpectedResponse
+		} else if value, ok := row[type_col].(string); ok == false {
+			return nil, ErrUnexpectedResponse
+		} else {
+			fields[key] = value
+		}
+	}
+	return fields, nil
+}
+
+// ParseTagKeys returns the tag keys from a server response to SHOW TAG
+// KEYS for one measurement
+func (r *Result) ParseTagKeys() ([]string, error) {
+	key_col := r.columnindex("tagKey")
+	if key_col < 0 {
+		return nil, ErrUnexpectedResponse
+	}
+	keys := make([]string, 0, len(r.Values))
+	for _, row := range r.Values {
+		if len(row) != len(r.Columns) {
+			return nil, ErrUnexpectedResponse
+		} else if key, ok := row[key_col].(string); ok == false {
+			return nil, ErrUnexpectedResponse
+		} else {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// ParseTagValues returns tag values from a server response to SHOW TAG
+// VALUES, grouped by tag key in the order returned by the server
+func (r *Result) ParseTagValues() (map[string][]string, error) {
+	key_col := r.columnindex("key")
+	value_col := r.columnindex("value")
+	if key_col < 0 || value_col < 0 {
+		return nil, ErrUnexpectedResponse
+	}
+	values := make(map[string][]string)
+	for _, row := range r.Values {
+		if len(row) != len(r.Columns) {
+			return nil, ErrUnexpectedResponse
+		} else if key, ok := row[key_col].(string); ok == false {
+			return nil, ErrUnexpectedResponse
+		} else if value, ok := row[value_col].(string); ok == false {
+			return nil, ErrUnexpectedResponse
+		} else {
+			values[key] = append(values[key], value)
+		}
+	}
+	return values, nil
+}
+
+// ParseUsers returns users from a server response to SHOW USERS, mapped
+// to true for admin users
+func (r *Result) ParseUsers() (map[string]bool, error) {
+	user_col, admin_col := r.columnindex("user"), r.columnindex("admin")
+	if user_col < 0 || admin_col < 0 {
+		return nil, ErrUnexpectedResponse
+	}
+	users := make(map[string]bool, len(r.Values))
+	for _, row := range r.Values {
+		if len(row) != len(r.Columns) {
+			return nil, ErrUnexpectedResponse
+		} else if user, ok := row[user_col].(string); ok == false {
+			return nil, ErrUnexpectedResponse
+		} else if admin, ok := toBool(row[admin_col]); ok == false {
+			return nil, ErrUnexpectedResponse
+		} else {
+			users[user] = admin
+		}
+	}
+	return users, nil
+}
+
+// ParseSubscriptions returns subscriptions from a server response to
+// SHOW SUBSCRIPTIONS, where each series is the subscriptions for the
+// database named by the series
+func (r *Result) ParseSubscriptions() ([]Subscription, error) {
+	policy_col, name_col, mode_col, destinations_col := r.columnindex("retention_policy"), r.columnindex("name"), r.columnindex("mode"), r.columnindex("destinations")
+	if policy_col < 0 || name_col < 0 || mode_col < 0 || destinations_col < 0 {
+		return nil, ErrUnexpectedResponse
+	}
+	subscriptions := make([]Subscription, 0, len(r.Values))
+	for _, row := range r.Values {
+		if len(row) != len(r.Columns) {
+			return nil, ErrUnexpectedResponse
+		}
+		policy, ok1 := row[policy_col].(string)
+		name, ok2 := row[name_col].(string)
+		mode, ok3 := row[mode_col].(string)
+		values, ok4 := row[destinations_col].([]interface{})
+		if ok1 == false || ok2 == false || ok3 == false || ok4 == false {
+			return nil, ErrUnexpectedResponse
+		}
+		destinations := make([]string, 0, len(values))
+		for _, value := range values {
+			if destination, ok := value.(string); ok == false {
+				return nil, ErrUnexpectedResponse
+			} else {
+				destinations = append(destinations, destination)
+			}
+		}
+		subscriptions = append(subscriptions, Subscription{
+			Database:        r.Name,
+			RetentionPolicy: policy,
+			Name:            name,
+			Mode:            mode,
+			Destinations:    destinations,
+		})
+	}
+	return subscriptions, nil
+}
+
+// ParseGrants returns grants from a server response to SHOW GRANTS
+func (r *Result) ParseGrants() ([]Grant, error) {
+	database_col, privilege_col := r.columnindex("database"), r.columnindex("privilege")
+	if database_col < 0 || privilege_col < 0 {
+		return nil, ErrUnexpectedResponse
+	}
+	grants := make([]Grant, 0, len(r.Values))
+	for _, row := range r.Values {
+		if len(row) != len(r.Columns) {
+			return nil, ErrUnexpectedResponse
+		} else if database, ok := row[database_col].(string); ok == false {
+			return nil, ErrUnexpectedResponse
+		} else if privilege, ok := row[privilege_col].(string); ok == false {
+			return nil, ErrUnexpectedResponse
+		} else {
+			grants = append(grants, Grant{Database: database, Privilege: privilege})
+		}
+	}
+	return grants, nil
+}
+
 // ParseRetentionPolicies returns retention policies from a server
-// response
+// response to SHOW RETENTION POLICIES. The shardGroupDuration column is
+// optional, and the default flag may be returned as a boolean, a number
+// or a string depending on the server version
 func (r *Result) ParseRetentionPolicies() (map[string]*RetentionPolicy, error) {
+	name_col, duration_col, shard_col := r.columnindex("name"), r.columnindex("duration"), r.columnindex("shardGroupDuration")
+	replication_col, default_col := r.columnindex("replicaN"), r.columnindex("default")
+	if name_col < 0 || duration_col < 0 || replication_col < 0 || default_col < 0 {
+		return nil, ErrUnexpectedResponse
+	}
 	policies := make(map[string]*RetentionPolicy, len(r.Values))
+	var err error
 	for _, row := range r.Values {
-		if len(row) != 5 {
+		if len(row) != len(r.Columns) {
 			return nil, ErrUnexpectedResponse
 		}
-		if name, ok := row[0].(string); ok == false {
+		policy := new(RetentionPolicy)
+		if name, ok := row[name_col].(string); ok == false {
 			return nil, ErrUnexpectedResponse
-		} else if duration, ok := row[1].(string); ok == false {
+		} else if duration, ok := row[duration_col].(string); ok == false {
 			return nil, ErrUnexpectedResponse
-		} else if shard_duration, ok := row[2].(string); ok == false {
+		} else if replication_factor, ok := toUint(row[replication_col]); ok == false {
 			return nil, ErrUnexpectedResponse
-		} else if replication_factor, ok := row[3].(json.Number); ok == false {
+		} else if default_policy, ok := toBool(row[default_col]); ok == false {
 			return nil, ErrUnexpectedResponse
-		} else if default_policy, ok := row[4].(bool); ok == false {
+		} else if policy.Duration, err = parseDuration(duration); err != nil {
 			return nil, ErrUnexpectedResponse
-		} else if duration2, err := time.ParseDuration(duration); err != nil {
+		} else {
+			policy.ReplicationFactor = int(replication_factor)
+			policy.Default = default_policy
+			policies[name] = policy
+		}
+		if shard_col >= 0 {
+			if shard_duration, ok := row[shard_col].(string); ok == false {
+				return nil, ErrUnexpectedResponse
+			} else if policy.ShardGroupDuration, err = parseDuration(shard_duration); err != nil {
+				return nil, ErrUnexpectedResponse
+			}
+		}
+	}
+	return policies, nil
+}
+
+// ParseRunningQueries returns running queries from a server response
+// to SHOW QUERIES
+func (r *Result) ParseRunningQueries() ([]*RunningQuery, error) {
+	qid_col, query_col, database_col, duration_col := r.columnindex("qid"), r.columnindex("query"), r.columnindex("database"), r.columnindex("duration")
+	if qid_col < 0 || query_col < 0 || database_col < 0 || duration_col < 0 {
+		return nil, ErrUnexpectedResponse
+	}
+	queries := make([]*RunningQuery, 0, len(r.Values))
+	for _, row := range r.Values {
+		if len(row) != len(r.Columns) {
 			return nil, ErrUnexpectedResponse
-		} else if shard_duration2, err := time.ParseDuration(shard_duration); err != nil {
+		}
+		if qid, ok := row[qid_col].(json.Number); ok == false {
+			return nil, ErrUnexpectedResponse
+		} else if query, ok := row[query_col].(string); ok == false {
+			return nil, ErrUnexpectedResponse
+		} else if duration, ok := row[duration_col].(string); ok == false {
+			return nil, ErrUnexpectedResponse
+		} else if qid2, err := strconv.ParseUint(qid.String(), 10, 64); err != nil {
 			return nil, ErrUnexpectedResponse
-		} else if replication_factor2, err := replication_factor.Int64(); err != nil {
+		} else if duration2, err := parseDuration(duration); err != nil {
 			return nil, ErrUnexpectedResponse
 		} else {
-			policies[name] = &RetentionPolicy{
-				Duration:           duration2,
-				ShardGroupDuration: shard_duration2,
-				ReplicationFactor:  int(replication_factor2),
-				Default:            default_policy,
-			}
+			// Database is empty for queries not run against a database
+			database, _ := row[database_col].(string)
+			queries = append(queries, &RunningQuery{
+				QID:      qid2,
+				Query:    query,
+				Database: database,
+				Duration: duration2,
+			})
 		}
 	}
-	return policies, nil
+	return queries, nil
+}
+
+// ParseShards returns shards from a server response to SHOW SHARDS
+func (r *Result) ParseShards() ([]*Shard, error) {
+	id_col, database_col, policy_col, group_col := r.columnindex("id"), r.columnindex("database"), r.columnindex("retention_policy"), r.columnindex("shard_group")
+	start_col, end_col, expiry_col := r.columnindex("start_time"), r.columnindex("end_time"), r.columnindex("expiry_time")
+	if id_col < 0 || database_col < 0 || policy_col < 0 || group_col < 0 || start_col < 0 || end_col < 0 || expiry_col < 0 {
+		return nil, ErrUnexpectedResponse
+	}
+	shards := make([]*Shard, 0, len(r.Values))
+	for _, row := range r.Values {
+		if len(row) != len(r.Columns) {
+			return nil, ErrUnexpectedResponse
+		}
+		shard := new(Shard)
+		if id, ok := toUint(row[id_col]); ok == false {
+			return nil, ErrUnexpectedResponse
+		} else if database, ok := row[database_col].(string); ok == false {
+			return nil, ErrUnexpectedResponse
+		} else if policy, ok := row[policy_col].(string); ok == false {
+			return nil, ErrUnexpectedResponse
+		} else if group, ok := toUint(row[group_col]); ok == false {
+			return nil, ErrUnexpectedResponse
+		} else if start, ok := toTime(row[start_col]); ok == false {
+			return nil, ErrUnexpectedResponse
+		} else if end, ok := toTime(row[end_col]); ok == false {
+			return nil, ErrUnexpectedResponse
+		} else if expiry, ok := toTime(row[expiry_col]); ok == false {
+			return nil, ErrUnexpectedResponse
+		} else {
+			shard.ID, shard.Database, shard.Policy, shard.ShardGroup = id, database, policy, group
+			shard.Start, shard.End, shard.Expiry = start, end, expiry
+		}
+		shards = append(shards, shard)
+	}
+	return shards, nil
+}
+
+// ParseShardGroups returns shard groups from a server response to
+// SHOW SHARD GROUPS
+func (r *Result) ParseShardGroups() ([]*ShardGroup, error) {
+	id_col, database_col, policy_col := r.columnindex("id"), r.columnindex("database"), r.columnindex("retention_policy")
+	start_col, end_col, expiry_col := r.columnindex("start_time"), r.columnindex("end_time"), r.columnindex("expiry_time")
+	if id_col < 0 || database_col < 0 || policy_col < 0 || start_col < 0 || end_col < 0 || expiry_col < 0 {
+		return nil, ErrUnexpectedResponse
+	}
+	groups := make([]*ShardGroup, 0, len(r.Values))
+	for _, row := range r.Values {
+		if len(row) != len(r.Columns) {
+			return nil, ErrUnexpectedResponse
+		}
+		group := new(ShardGroup)
+		if id, ok := toUint(row[id_col]); ok == false {
+			return nil, ErrUnexpectedResponse
+		} else if database, ok := row[database_col].(string); ok == false {
+			return nil, ErrUnexpectedResponse
+		} else if policy, ok := row[policy_col].(string); ok == false {
+			return nil, ErrUnexpectedResponse
+		} else if start, ok := toTime(row[start_col]); ok == false {
+			return nil, ErrUnexpectedResponse
+		} else if end, ok := toTime(row[end_col]); ok == false {
+			return nil, ErrUnexpectedResponse
+		} else if expiry, ok := toTime(row[expiry_col]); ok == false {
+			return nil, ErrUnexpectedResponse
+		} else {
+			group.ID, group.Database, group.Policy = id, database, policy
+			group.Start, group.End, group.Expiry = start, end, expiry
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// ParseBuildInfo returns the build information from the "build" series
+// of a server response to SHOW DIAGNOSTICS
+func (r *Result) ParseBuildInfo() (*BuildInfo, error) {
+	version_col, branch_col := r.columnindex("Version"), r.columnindex("Branch")
+	commit_col, time_col := r.columnindex("Commit"), r.columnindex("Build Time")
+	if version_col < 0 || len(r.Values) != 1 || len(r.Values[0]) != len(r.Columns) {
+		return nil, ErrUnexpectedResponse
+	}
+	row := r.Values[0]
+	info := new(BuildInfo)
+	if version, ok := row[version_col].(string); ok == false {
+		return nil, ErrUnexpectedResponse
+	} else {
+		info.Version = version
+	}
+	for _, field := range []struct {
+		col   int
+		value *string
+	}{{branch_col, &info.Branch}, {commit_col, &info.Commit}, {time_col, &info.BuildTime}} {
+		if field.col < 0 || row[field.col] == nil {
+			continue
+		} else if value, ok := row[field.col].(string); ok == false {
+			return nil, ErrUnexpectedResponse
+		} else {
+			*field.value = value
+		}
+	}
+	return info, nil
 }
 
 ////////////////////////////////////////////////////////////////////////////////
 // PRIVATE METHODS
 
+// toUint returns an unsigned integer from a server response value
+func toUint(value interface{}) (uint64, bool) {
+	if number, ok := value.(json.Number); ok == false {
+		return 0, false
+	} else if n, err := strconv.ParseUint(number.String(), 10, 64); err != nil {
+		return 0, false
+	} else {
+		return n, true
+	}
+}
+
+// toBool returns a boolean from a server response value, which may be a
+// boolean, the number 1 or 0, or the string "true" or "false"
+func toBool(value interface{}) (bool, bool) {
+	switch value := value.(type) {
+	case bool:
+		return value, true
+	case json.Number:
+		if n, err := strconv.ParseInt(value.String(), 10, 64); err == nil && (n == 0 || n == 1) {
+			return n == 1, true
+		}
+	case string:
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b, true
+		}
+	}
+	return false, false
+}
+
+// valueType returns the column type of a server response value
+func valueType(value interface{}) string {
+	switch value := value.(type) {
+	case bool:
+		return "boolean"
+	case json.Number:
+		if _, err := strconv.ParseInt(value.String(), 10, 64); err == nil {
+			return "integer"
+		}
+		return "float"
+	case float32, float64:
+		return "float"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return "integer"
+	case time.Time:
+		return "time"
+	case string:
+		if _, ok := toTime(value); ok {
+			return "time"
+		}
+	}
+	return "string"
+}
+
+// mergeColumnType returns the type of a column containing values of two
+// types
+func mergeColumnType(a, b string) string {
+	switch {
+	case a == "" || a == b:
+		return b
+	case (a == "integer" && b == "float") || (a == "float" && b == "integer"):
+		return "float"
+	default:
+		return "string"
+	}
+}
+
+// equalValue returns true if two values are equal, comparing numbers by
+// value and times as instants
+func equalValue(a, b interface{}) bool {
+	if a_number, ok := toNumber(a); ok {
+		b_number, ok := toNumber(b)
+		return ok && a_number == b_number
+	} else if a_time, ok := a.(time.Time); ok {
+		b_time, ok := b.(time.Time)
+		return ok && a_time.Equal(b_time)
+	} else {
+		return reflect.DeepEqual(a, b)
+	}
+}
+
+// toNumber returns a numeric value in a canonical form, which is the
+// decimal integer for integral values or the shortest float otherwise
+func toNumber(value interface{}) (string, bool) {
+	switch value := value.(type) {
+	case json.Number:
+		if n, err := strconv.ParseInt(value.String(), 10, 64); err == nil {
+			return strconv.FormatInt(n, 10), true
+		} else if n, err := strconv.ParseUint(value.String(), 10, 64); err == nil {
+			return strconv.FormatUint(n, 10), true
+		} else if f, err := value.Float64(); err == nil {
+			return toNumber(f)
+		}
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(value), true
+	case float32:
+		return toNumber(float64(value))
+	case float64:
+		if value == math.Trunc(value) && math.Abs(value) < 1<<63 {
+			return strconv.FormatInt(int64(value), 10), true
+		}
+		return strconv.FormatFloat(value, 'g', -1, 64), true
+	}
+	return "", false
+}
+
+// toTime returns a time from an RFC3339 server response value
+func toTime(value interface{}) (time.Time, bool) {
+	if str, ok := value.(string); ok == false {
+		return time.Time{}, false
+	} else if t, err := time.Parse(time.RFC3339Nano, str); err != nil {
+		return time.Time{}, false
+	} else {
+		return t, true
+	}
+}
+
+// parseDuration parses durations in the InfluxDB format, which extends the
+// time.ParseDuration format with days and weeks (for example, "1w2d" or "51µs")
+func parseDuration(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, ErrBadParameter
+	}
+	duration := time.Duration(0)
+	for value != "" {
+		if match := regexpDurationUnit.FindStringSubmatch(value); match == nil {
+			return 0, ErrBadParameter
+		} else if n, err := strconv.ParseInt(match[1], 10, 64); err != nil {
+			return 0, ErrBadParameter
+		} else {
+			duration += time.Duration(n) * durationUnits[match[2]]
+			value = value[len(match[0]):]
+		}
+	}
+	return duration, nil
+}
+
 func (r *Result) columnindex(column string) int {
 	for i := range r.Columns {
 		if r.Columns[i] == column {
@@ -81,7 +808,7 @@ func (r *Result) columnindex(column string) int {
 }
 
 func (r *Result) column(column string) ([]Value, error) {
-	if i := r.columnindex(column); i >= 0 && i < len(r.Columns) {
+	if i := r.ColumnIndex(column); i >= 0 && i < len(r.Columns) {
 		c := make([]Value, len(r.Values))
 		for j := range r.Values {
 			c[j] = toValue(column, r.Values[j][i])
@@ -107,7 +834,6 @@ func toValue(col string, value interface{}) Value {
 	case string:
 		if col == "time" {
 			if t, err := time.Parse(time.RFC3339Nano, value.(string)); err == nil {
-				fmt.Println("time=", value, t)
 				return Value(t)
 			}
 		}
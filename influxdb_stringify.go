@@ -9,6 +9,7 @@ package influxdb
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -36,3 +37,34 @@ func (r *Result) String() string {
 func (this *RetentionPolicy) String() string {
 	return fmt.Sprintf("<influxdb.RetentionPolicy>{ Duration=%v ShardGroupDuration=%v ReplicationFactor=%v Default=%v }", this.Duration, this.ShardGroupDuration, this.ReplicationFactor, this.Default)
 }
+
+func (this *RunningQuery) String() string {
+	return fmt.Sprintf("<influxdb.RunningQuery>{ QID=%v Query=%v Database=%v Duration=%v }", this.QID, strconv.Quote(this.Query), this.Database, this.Duration)
+}
+
+func (this *Point) String() string {
+	return fmt.Sprintf("<influxdb.Point>{ Measurement=%v Tags=%v Fields=%v Time=%v }", this.Measurement, this.Tags, this.Fields, this.Time)
+}
+
+func (this *Shard) String() string {
+	return fmt.Sprintf("<influxdb.Shard>{ ID=%v Database=%v Policy=%v ShardGroup=%v Start=%v End=%v Expiry=%v }", this.ID, this.Database, this.Policy, this.ShardGroup, this.Start, this.End, this.Expiry)
+}
+
+func (this *ShardGroup) String() string {
+	return fmt.Sprintf("<influxdb.ShardGroup>{ ID=%v Database=%v Policy=%v Start=%v End=%v Expiry=%v }", this.ID, this.Database, this.Policy, this.Start, this.End, this.Expiry)
+}
+
+func (k ErrorKind) String() string {
+	switch k {
+	case ErrorFieldTypeConflict:
+		return "ErrorFieldTypeConflict"
+	case ErrorRetentionPolicyNotFound:
+		return "ErrorRetentionPolicyNotFound"
+	case ErrorDatabaseNotFound:
+		return "ErrorDatabaseNotFound"
+	case ErrorAuthFailure:
+		return "ErrorAuthFailure"
+	default:
+		return "ErrorUnknown"
+	}
+}
@@ -1,7 +1,21 @@
 package influxdb_test
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -20,17 +34,17 @@ func ServerHost() string {
 	return "rpi3.lan"
 }
 
-func Driver(t *testing.T, db string) influxdb.Driver {
+func Driver(t *testing.T, db string) influxdb.Client {
 	return ActualDriver(t, db)
 }
 
-func MockDriver(t *testing.T, db string) influxdb.Driver {
+func MockDriver(t *testing.T, db string) influxdb.Client {
 	configuration := mock.Config{Database: db}
 	if log, err := gopi.Open(logger.Config{}, nil); err != nil {
 		t.Error(err)
 	} else if client, err := gopi.Open(configuration, log.(gopi.Logger)); err != nil {
 		t.Error(err)
-	} else if driver, ok := client.(influxdb.Driver); ok == false {
+	} else if driver, ok := client.(influxdb.Client); ok == false {
 		t.Fatal("mock client does not implement all the required methods")
 	} else {
 		return driver
@@ -38,7 +52,7 @@ func MockDriver(t *testing.T, db string) influxdb.Driver {
 	return nil
 }
 
-func ActualDriver(t *testing.T, db string) influxdb.Driver {
+func ActualDriver(t *testing.T, db string) influxdb.Client {
 	configuration := v2.Config{
 		Database: db,
 		Host:     ServerHost(),
@@ -48,8 +62,123 @@ func ActualDriver(t *testing.T, db string) influxdb.Driver {
 		t.Error(err)
 	} else if client, err := gopi.Open(configuration, log.(gopi.Logger)); err != nil {
 		t.Error(err)
-	} else if driver, ok := client.(influxdb.Driver); ok == false {
-		_ = client.(influxdb.Driver)
+	} else if driver, ok := client.(influxdb.Client); ok == false {
+		_ = client.(influxdb.Client)
+		t.Fatal("v2 client does not implement all the required methods")
+	} else {
+		return driver
+	}
+	return nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// StubServer responds to InfluxDB API requests with canned responses keyed
+// by statement, and records the statements received. Handlers can be keyed
+// by statement or by path, to replace an endpoint
+type StubServer struct {
+	*httptest.Server
+	sync.Mutex
+
+	Version   string
+	Responses map[string]string
+	Handlers  map[string]http.HandlerFunc
+	Queries   []string
+	Writes    []*StubWrite
+
+	// ConnState when not nil is called when a client connection changes
+	// state, and should be set with the lock held
+	ConnState func(net.Conn, http.ConnState)
+}
+
+// StubWrite records the parameters and line protocol for a write
+type StubWrite struct {
+	Values url.Values
+	Lines  []string
+}
+
+func NewStubServer() *StubServer {
+	this := &StubServer{
+		Version:   "1.5.2",
+		Responses: make(map[string]string),
+		Handlers:  make(map[string]http.HandlerFunc),
+	}
+	this.Responses["SHOW DATABASES"] = `{"results":[{"statement_id":0,"series":[{"name":"databases","columns":["name"],"values":[["_internal"],["test"]]}]}]}`
+	this.Server = httptest.NewUnstartedServer(this)
+	this.Server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		this.Lock()
+		fn := this.ConnState
+		this.Unlock()
+		if fn != nil {
+			fn(conn, state)
+		}
+	}
+	this.Server.Start()
+	return this
+}
+
+func (this *StubServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	this.Lock()
+	handler, exists := this.Handlers[r.URL.Path]
+	this.Unlock()
+	if exists {
+		handler(w, r)
+		return
+	}
+	switch r.URL.Path {
+	case "/ping":
+		w.Header().Set("X-Influxdb-Version", this.Version)
+		w.WriteHeader(http.StatusNoContent)
+	case "/query":
+		q := r.FormValue("q")
+		this.Lock()
+		this.Queries = append(this.Queries, q)
+		handler, exists := this.Handlers[q]
+		response, _ := this.Responses[q]
+		this.Unlock()
+		if exists {
+			handler(w, r)
+		} else if response != "" {
+			w.Write([]byte(response))
+		} else {
+			w.Write([]byte(`{"results":[{"statement_id":0}]}`))
+		}
+	case "/write":
+		body, _ := ioutil.ReadAll(r.Body)
+		this.Lock()
+		this.Writes = append(this.Writes, &StubWrite{r.URL.Query(), strings.Split(strings.TrimSpace(string(body)), "\n")})
+		this.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (this *StubServer) HasQuery(q string) bool {
+	this.Lock()
+	defer this.Unlock()
+	for _, query := range this.Queries {
+		if query == q {
+			return true
+		}
+	}
+	return false
+}
+
+func StubDriver(t *testing.T, server *StubServer, db string) influxdb.Client {
+	return StubDriverConfig(t, server, v2.Config{Database: db})
+}
+
+func StubDriverConfig(t *testing.T, server *StubServer, configuration v2.Config) influxdb.Client {
+	u, _ := url.Parse(server.URL)
+	port, _ := strconv.ParseUint(u.Port(), 10, 32)
+	configuration.Host = u.Hostname()
+	configuration.Port = uint(port)
+	if log, err := gopi.Open(logger.Config{}, nil); err != nil {
+		t.Error(err)
+	} else if client, err := gopi.Open(configuration, log.(gopi.Logger)); err != nil {
+		t.Error(err)
+	} else if driver, ok := client.(influxdb.Client); ok == false {
 		t.Fatal("v2 client does not implement all the required methods")
 	} else {
 		return driver
@@ -112,6 +241,22 @@ func TestQuote_000(t *testing.T) {
 	}
 }
 
+func TestQuote_001(t *testing.T) {
+	for _, value := range []string{"", "a", "test string", "test \"string\"", "test \\string\"", "\\", "\"", "\"\"", "SELECT", "a\\\"b c"} {
+		if actual := influxdb.Unquote(influxdb.Quote(value)); actual != value {
+			t.Errorf("For [%v], Unquote(Quote) returned [%v]", value, actual)
+		}
+		if actual := influxdb.Unquote(influxdb.QuoteString(value)); actual != value {
+			t.Errorf("For [%v], Unquote(QuoteString) returned [%v]", value, actual)
+		}
+	}
+	for _, value := range []string{"cpu", "\"", "a\"b"} {
+		if actual := influxdb.Unquote(value); actual != value {
+			t.Errorf("For [%v], expected unchanged, got [%v]", value, actual)
+		}
+	}
+}
+
 func TestQueries_003(t *testing.T) {
 	query := influxdb.ShowDatabases()
 	if query.String() != "SHOW DATABASES" {
@@ -483,7 +628,7 @@ func TestCreateRetentionPolicy_003(t *testing.T) {
 		} else if policy, exists := policies["policy"]; exists == false {
 			t.Error("Missing policy after being created")
 		} else if policy.Duration != time.Hour*1 {
-			t.Error("Invalid policy time, unexpected value %v", policy.Duration)
+			t.Errorf("Invalid policy time, unexpected value %v", policy.Duration)
 		}
 	}
 }
@@ -594,3 +739,3426 @@ func TestWhere_002(t *testing.T) {
 		}
 	}
 }
+
+func TestQueryWithServerTimeout_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	server.Handlers["SELECT * FROM slow"] = func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}
+	server.Responses["SHOW QUERIES"] = `{"results":[{"statement_id":0,"series":[{"columns":["qid","query","database","duration"],"values":[[41,"SHOW QUERIES","test","51µs"],[43,"SELECT * FROM slow","test","10ms"],[42,"SELECT * FROM slow","test","100ms"],[44,"SELECT * FROM slow","other","1s"]]}]}]}`
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		if _, err := driver.QueryWithServerTimeout("SELECT * FROM slow", 100*time.Millisecond); err != context.DeadlineExceeded {
+			t.Error("Expected DeadlineExceeded, got", err)
+		} else if server.HasQuery("KILL QUERY 42") == false {
+			t.Error("Expected KILL QUERY 42, got", server.Queries)
+		} else if server.HasQuery("KILL QUERY 43") || server.HasQuery("KILL QUERY 44") {
+			t.Error("Expected only the timed out query on the database to be killed, got", server.Queries)
+		}
+	}
+}
+
+func TestQueryWithServerTimeout_002(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	server.Handlers["SELECT * FROM slow"] = func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}
+
+	// Another client has been running the same statement for longer
+	server.Responses["SHOW QUERIES"] = `{"results":[{"statement_id":0,"series":[{"columns":["qid","query","database","duration"],"values":[[50,"SELECT * FROM slow","test","5s"],[51,"SELECT * FROM slow","test","120ms"],[52,"SELECT * FROM slow","test","20ms"]]}]}]}`
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		if _, err := driver.QueryWithServerTimeout("SELECT * FROM slow", 100*time.Millisecond); err != context.DeadlineExceeded {
+			t.Error("Expected DeadlineExceeded, got", err)
+		} else if server.HasQuery("KILL QUERY 51") == false {
+			t.Error("Expected KILL QUERY 51, got", server.Queries)
+		} else if server.HasQuery("KILL QUERY 50") || server.HasQuery("KILL QUERY 52") {
+			t.Error("Expected only the query running for about the timeout to be killed, got", server.Queries)
+		}
+	}
+}
+
+func TestQueries_029(t *testing.T) {
+	query := influxdb.ShowQueries()
+	if query.String() != "SHOW QUERIES" {
+		t.Errorf("Unexpected query: %v", query.String())
+	}
+}
+
+func TestQueries_030(t *testing.T) {
+	query := influxdb.KillQuery(36)
+	if query.String() != "KILL QUERY 36" {
+		t.Errorf("Unexpected query: %v", query.String())
+	}
+}
+
+func TestShowQueries_001(t *testing.T) {
+	result := &influxdb.Result{
+		Columns: []string{"qid", "query", "database", "duration", "status"},
+		Values: [][]interface{}{
+			{json.Number("36"), "SHOW QUERIES", "", "43µs", "running"},
+			{json.Number("37"), "SELECT mean(value) FROM cpu", "telegraf", "1m30s", "running"},
+			{json.Number("38"), "SELECT * FROM mem", "telegraf", "2d3h", "running"},
+		},
+	}
+	if queries, err := result.ParseRunningQueries(); err != nil {
+		t.Error(err)
+	} else if len(queries) != 3 {
+		t.Error("Expected three queries, got", len(queries))
+	} else {
+		if queries[0].QID != 36 || queries[0].Database != "" || queries[0].Duration != 43*time.Microsecond {
+			t.Error("Unexpected query:", queries[0])
+		}
+		if queries[1].QID != 37 || queries[1].Query != "SELECT mean(value) FROM cpu" || queries[1].Database != "telegraf" || queries[1].Duration != 90*time.Second {
+			t.Error("Unexpected query:", queries[1])
+		}
+		if queries[2].QID != 38 || queries[2].Duration != 51*time.Hour {
+			t.Error("Unexpected query:", queries[2])
+		}
+	}
+}
+
+func TestQueryConcurrent_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	var inflight, peak int32
+	queries := make([]influxdb.Query, 6)
+	for i := range queries {
+		name := "m" + strconv.Itoa(i)
+		queries[i] = influxdb.Select(&influxdb.Measurement{Name: name})
+		server.Handlers[queries[i].String()] = func(w http.ResponseWriter, r *http.Request) {
+			n := atomic.AddInt32(&inflight, 1)
+			defer atomic.AddInt32(&inflight, -1)
+			for {
+				if p := atomic.LoadInt32(&peak); n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			w.Write([]byte(`{"results":[{"statement_id":0,"series":[{"name":"` + name + `","columns":["time","value"],"values":[[0,1]]}]}]}`))
+		}
+	}
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		if results, err := driver.QueryConcurrent(queries, 2); err != nil {
+			t.Error(err)
+		} else if len(results) != len(queries) {
+			t.Error("Expected", len(queries), "results, got", len(results))
+		} else {
+			for i, r := range results {
+				if expected := "m" + strconv.Itoa(i); len(r) != 1 || r[0].Name != expected {
+					t.Error("Expected result", expected, "got", r)
+				}
+			}
+			if peak > 2 {
+				t.Error("Expected at most 2 queries in flight, got", peak)
+			}
+		}
+	}
+}
+
+func TestQueryConcurrent_002(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	server.Responses["SELECT * FROM bad"] = `{"results":[{"statement_id":0,"error":"measurement not found"}]}`
+	server.Responses["SELECT * FROM good"] = `{"results":[{"statement_id":0,"series":[{"name":"good","columns":["time","value"],"values":[[0,1]]}]}]}`
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		queries := []influxdb.Query{
+			influxdb.Select(&influxdb.Measurement{Name: "bad"}),
+			influxdb.Select(&influxdb.Measurement{Name: "good"}),
+		}
+		if results, err := driver.QueryConcurrent(queries, 4); err == nil {
+			t.Error("Expected error from failed query")
+		} else if results[0] != nil || len(results[1]) != 1 {
+			t.Error("Unexpected results:", results)
+		}
+	}
+}
+
+func TestAggregate_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	aggregates := map[string]string{
+		"mean":  "SELECT mean(value) FROM cpu WHERE time > now() - 1h",
+		"max":   "SELECT max(value) FROM cpu WHERE time > now() - 1h",
+		"min":   "SELECT min(value) FROM cpu WHERE time > now() - 1h",
+		"sum":   "SELECT sum(value) FROM cpu WHERE time > now() - 1h",
+		"count": "SELECT count(value) FROM cpu WHERE time > now() - 1h",
+	}
+	for name, q := range aggregates {
+		server.Responses[q] = `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","` + name + `"],"values":[["1970-01-01T00:00:00Z",42.5]]}]}]}`
+	}
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		functions := map[string]func(string, string, time.Duration) (float64, error){
+			"mean": driver.Mean, "max": driver.Max, "min": driver.Min, "sum": driver.Sum, "count": driver.Count,
+		}
+		for name, fn := range functions {
+			if value, err := fn("cpu", "value", time.Hour); err != nil {
+				t.Error(name, err)
+			} else if value != 42.5 {
+				t.Error(name, "unexpected value", value)
+			} else if server.HasQuery(aggregates[name]) == false {
+				t.Error(name, "expected query", aggregates[name])
+			}
+		}
+	}
+}
+
+func TestAggregate_002(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		if _, err := driver.Mean("cpu", "value", time.Minute*90); err != influxdb.ErrEmptyResponse {
+			t.Error("Expected ErrEmptyResponse, got", err)
+		} else if server.HasQuery("SELECT mean(value) FROM cpu WHERE time > now() - 90m") == false {
+			t.Error("Unexpected queries", server.Queries)
+		}
+	}
+}
+
+func TestAggregate_003(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	server.Responses["SELECT percentile(latency,99.5) FROM http WHERE time > now() - 1d"] = `{"results":[{"statement_id":0,"series":[{"name":"http","columns":["time","percentile"],"values":[["1970-01-01T00:00:00Z",210]]}]}]}`
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		if value, err := driver.Percentile("http", "latency", 99.5, time.Hour*24); err != nil {
+			t.Error(err)
+		} else if value != 210 {
+			t.Error("Unexpected value", value)
+		}
+		for _, p := range []float64{-1, 100.1} {
+			if _, err := driver.Percentile("http", "latency", p, time.Hour); err != influxdb.ErrBadParameter {
+				t.Error("Expected ErrBadParameter for", p, "got", err)
+			}
+		}
+	}
+}
+
+func TestWhere_003(t *testing.T) {
+	if p := influxdb.Percentile("value", 95); p.String() != "percentile(value,95)" {
+		t.Error("Expected string, got", p.String())
+	}
+	if p := influxdb.Percentile("my value", 99.9); p.String() != "percentile(\"my value\",99.9)" {
+		t.Error("Expected string, got", p.String())
+	}
+}
+
+func TestParseMeasurement_001(t *testing.T) {
+	tests := map[string]influxdb.Measurement{
+		"cpu":                            {Name: "cpu"},
+		"autogen.cpu":                    {Name: "cpu", Policy: "autogen"},
+		"\"autogen\".cpu":                {Name: "cpu", Policy: "autogen"},
+		"db.autogen.cpu":                 {Name: "cpu", Policy: "autogen", Database: "db"},
+		"\"db\"..\"cpu\"":                {Name: "cpu", Database: "db"},
+		"\"db\".\"rp\".\"cpu\"":          {Name: "cpu", Policy: "rp", Database: "db"},
+		"\"cpu.load\"":                   {Name: "cpu.load"},
+		"\"my db\".\"auto gen\".\"cpu\"": {Name: "cpu", Policy: "auto gen", Database: "my db"},
+		"\"say \\\"hi\\\".x\"":           {Name: "say \"hi\".x"},
+		"\"température\"":                {Name: "température"},
+	}
+	for value, expected := range tests {
+		if measurement, err := influxdb.ParseMeasurement(value); err != nil {
+			t.Error(value, err)
+		} else if *measurement != expected {
+			t.Errorf("For [%v], expected %v, got %v", value, expected, measurement)
+		}
+	}
+	for _, value := range []string{"", "a.b.c.d", "autogen.", "db.rp.", "\"cpu", "\"cpu\"x", "cp\"u\""} {
+		if _, err := influxdb.ParseMeasurement(value); err != influxdb.ErrBadParameter {
+			t.Errorf("For [%v], expected ErrBadParameter, got %v", value, err)
+		}
+	}
+}
+
+func TestWriteBatch_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		if err := driver.WriteBatch([]*influxdb.Point{
+			{Measurement: "\"autogen\".cpu", Fields: map[string]interface{}{"value": 1.0}, Time: time.Unix(1, 0)},
+			{Measurement: "mem", Fields: map[string]interface{}{"value": 2.0}, Time: time.Unix(2, 0)},
+			{Measurement: "autogen.cpu", Fields: map[string]interface{}{"value": 3.0}, Time: time.Unix(3, 0)},
+		}); err != nil {
+			t.Error(err)
+		} else if len(server.Writes) != 2 {
+			t.Error("Expected two writes, got", len(server.Writes))
+		} else if rp := server.Writes[0].Values.Get("rp"); rp != "autogen" {
+			t.Error("Expected rp=autogen, got", rp)
+		} else if len(server.Writes[0].Lines) != 2 {
+			t.Error("Expected two points written to autogen, got", server.Writes[0].Lines)
+		} else if rp := server.Writes[1].Values.Get("rp"); rp != "" {
+			t.Error("Expected default rp, got", rp)
+		} else if db := server.Writes[1].Values.Get("db"); db != "test" {
+			t.Error("Expected db=test, got", db)
+		}
+		if err := driver.WritePoint(&influxdb.Point{Measurement: "a.b.c.d", Fields: map[string]interface{}{"value": 1.0}}); err != influxdb.ErrBadParameter {
+			t.Error("Expected ErrBadParameter, got", err)
+		}
+	}
+}
+
+func TestWhere_004(t *testing.T) {
+	if where, err := influxdb.WhereSince(time.Hour); err != nil {
+		t.Error(err)
+	} else if where.String() != "time > now() - 1h" {
+		t.Error("Expected string, got", where.String())
+	}
+	if where, err := influxdb.WhereSince(time.Hour * 48); err != nil {
+		t.Error(err)
+	} else if where.String() != "time > now() - 2d" {
+		t.Error("Expected string, got", where.String())
+	}
+	if where, err := influxdb.WhereSince(time.Millisecond * 1500); err != nil {
+		t.Error(err)
+	} else if where.String() != "time > now() - 1500ms" {
+		t.Error("Expected string, got", where.String())
+	}
+	if where, err := influxdb.WhereBetweenNow(time.Hour*2, time.Minute*30); err != nil {
+		t.Error(err)
+	} else if where.String() != "time > now() - 2h AND time < now() - 30m" {
+		t.Error("Expected string, got", where.String())
+	}
+	if _, err := influxdb.WhereSince(-time.Hour); errors.Is(err, influxdb.ErrBadParameter) == false {
+		t.Error("Expected ErrBadParameter, got", err)
+	}
+	if _, err := influxdb.WhereBetweenNow(time.Minute*30, time.Hour*2); errors.Is(err, influxdb.ErrBadParameter) == false {
+		t.Error("Expected ErrBadParameter, got", err)
+	}
+	if _, err := influxdb.WhereBetweenNow(time.Hour, time.Hour); errors.Is(err, influxdb.ErrBadParameter) == false {
+		t.Error("Expected ErrBadParameter, got", err)
+	}
+	if _, err := influxdb.WhereBetweenNow(time.Hour, -time.Hour); errors.Is(err, influxdb.ErrBadParameter) == false {
+		t.Error("Expected ErrBadParameter, got", err)
+	}
+}
+
+func TestQueries_031(t *testing.T) {
+	where, err := influxdb.WhereBetweenNow(time.Hour*24*7, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := influxdb.Select(&influxdb.Measurement{Name: "cpu"}).Filter(influxdb.TagEquals("host", "a"), where)
+	if query.String() != "SELECT * FROM cpu WHERE host = \"a\" AND time > now() - 1w AND time < now() - 0s" {
+		t.Errorf("Unexpected query: %v", query.String())
+	}
+}
+
+func TestHealthCheck_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, ""); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		// Healthy
+		server.Handlers["/health"] = func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"name":"influxdb","message":"ready for queries and writes","status":"pass","checks":[],"version":"1.8.0"}`))
+		}
+		if err := driver.HealthCheck(context.Background()); err != nil {
+			t.Error(err)
+		}
+
+		// Unhealthy
+		server.Handlers["/health"] = func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"name":"influxdb","message":"not ready","status":"fail","checks":[],"version":"1.8.0"}`))
+		}
+		if err := driver.HealthCheck(context.Background()); errors.Is(err, influxdb.ErrUnhealthy) == false {
+			t.Error("Expected ErrUnhealthy, got", err)
+		}
+
+		// Older server falls back to ping
+		delete(server.Handlers, "/health")
+		if err := driver.HealthCheck(context.Background()); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestQueries_032(t *testing.T) {
+	query := influxdb.Select(&influxdb.Measurement{Name: "cpu"}).Into(&influxdb.Measurement{Name: "cpu_copy", Policy: "autogen"}).GroupBy("*")
+	if query.String() != "SELECT * INTO autogen.cpu_copy FROM cpu GROUP BY *" {
+		t.Errorf("Unexpected query: %v", query.String())
+	}
+}
+
+func TestCopyMeasurement_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		if err := driver.CopyMeasurement("cpu", "cpu load", influxdb.TagEquals("host", "a")); err != nil {
+			t.Error(err)
+		} else if q := "SELECT * INTO \"cpu load\" FROM cpu WHERE host = \"a\" GROUP BY *"; server.HasQuery(q) == false {
+			t.Error("Expected", q, "got", server.Queries)
+		}
+		if err := driver.CopyMeasurement("cpu", "a.b.c.d"); err != influxdb.ErrBadParameter {
+			t.Error("Expected ErrBadParameter, got", err)
+		}
+	}
+}
+
+func TestDryRun_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriverConfig(t, server, v2.Config{Database: "test", DryRun: true}); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		var dryrun *influxdb.DryRunError
+		if err := driver.DropDatabase("test"); errors.Is(err, influxdb.ErrDryRun) == false {
+			t.Error("Expected ErrDryRun, got", err)
+		} else if errors.As(err, &dryrun) == false || dryrun.Statement != "DROP DATABASE test" {
+			t.Error("Unexpected statement", err)
+		} else if server.HasQuery("DROP DATABASE test") {
+			t.Error("Unexpected query issued in dry run")
+		}
+
+		// Kill query is also destructive
+		if err := driver.KillQuery(36); errors.Is(err, influxdb.ErrDryRun) == false {
+			t.Error("Expected ErrDryRun, got", err)
+		} else if server.HasQuery("KILL QUERY 36") {
+			t.Error("Unexpected query issued in dry run")
+		}
+
+		// Override dry run for a single call
+		if err := driver.WithDryRun(false).DropDatabase("test"); err != nil {
+			t.Error(err)
+		} else if server.HasQuery("DROP DATABASE test") == false {
+			t.Error("Expected query to be issued")
+		} else if driver.DryRun() == false {
+			t.Error("Expected dry run to be unchanged")
+		}
+
+		// Override dry run for all calls
+		driver.SetDryRun(false)
+		if err := driver.DropDatabase("test"); err != nil {
+			t.Error(err)
+		} else if server.HasQuery("DROP DATABASE test") == false {
+			t.Error("Expected query to be issued")
+		}
+	}
+}
+
+func TestShowShards_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	server.Responses["SHOW SHARDS"] = `{"results":[{"statement_id":0,"series":[` +
+		`{"name":"_internal","columns":["id","database","retention_policy","shard_group","start_time","end_time","expiry_time","owners"],"values":[[1,"_internal","monitor",1,"2018-01-01T00:00:00Z","2018-01-02T00:00:00Z","2018-01-09T00:00:00Z",""]]},` +
+		`{"name":"telegraf","columns":["id","database","retention_policy","shard_group","start_time","end_time","expiry_time","owners"],"values":[[2,"telegraf","autogen",2,"2017-12-25T00:00:00Z","2018-01-01T00:00:00Z","2018-01-01T00:00:00Z",""],[3,"telegraf","autogen",3,"2018-01-01T00:00:00Z","2018-01-08T00:00:00Z","2018-01-08T00:00:00Z",""]]}]}]}`
+	if driver := StubDriver(t, server, ""); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		if shards, err := driver.ShowShards(); err != nil {
+			t.Error(err)
+		} else if len(shards) != 2 || len(shards["_internal"]) != 1 || len(shards["telegraf"]) != 2 {
+			t.Error("Unexpected shards", shards)
+		} else if shard := shards["telegraf"][1]; shard.ID != 3 || shard.Policy != "autogen" || shard.ShardGroup != 3 {
+			t.Error("Unexpected shard", shard)
+		} else if shard.Start.Equal(time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)) == false || shard.Expiry.Equal(time.Date(2018, 1, 8, 0, 0, 0, 0, time.UTC)) == false {
+			t.Error("Unexpected shard times", shard)
+		}
+	}
+}
+
+func TestShowShardGroups_001(t *testing.T) {
+	result := &influxdb.Result{
+		Name:    "shard groups",
+		Columns: []string{"id", "database", "retention_policy", "start_time", "end_time", "expiry_time"},
+		Values: [][]interface{}{
+			{json.Number("1"), "_internal", "monitor", "2018-01-01T00:00:00Z", "2018-01-02T00:00:00Z", "2018-01-09T00:00:00Z"},
+			{json.Number("2"), "telegraf", "autogen", "2017-12-25T00:00:00Z", "2018-01-01T00:00:00Z", "2018-01-01T00:00:00Z"},
+		},
+	}
+	if groups, err := result.ParseShardGroups(); err != nil {
+		t.Error(err)
+	} else if len(groups) != 2 {
+		t.Error("Expected two shard groups, got", len(groups))
+	} else if groups[1].ID != 2 || groups[1].Database != "telegraf" || groups[1].End.Equal(time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)) == false {
+		t.Error("Unexpected shard group", groups[1])
+	}
+
+	// Malformed time
+	result.Values[0][3] = "yesterday"
+	if _, err := result.ParseShardGroups(); err != influxdb.ErrUnexpectedResponse {
+		t.Error("Expected ErrUnexpectedResponse, got", err)
+	}
+}
+
+func TestLastValue_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	server.Responses["SELECT last(temperature) FROM weather"] = `{"results":[{"statement_id":0,"series":[{"name":"weather","columns":["time","last"],"values":[["2018-01-02T03:04:05Z",21.5]]}]}]}`
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		if ts, value, err := driver.LastValue("weather", "temperature"); err != nil {
+			t.Error(err)
+		} else if ts.Equal(time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)) == false {
+			t.Error("Unexpected timestamp", ts)
+		} else if value != 21.5 {
+			t.Error("Unexpected value", value)
+		}
+		if _, _, err := driver.LastValue("empty", "temperature"); err != influxdb.ErrEmptyResponse {
+			t.Error("Expected ErrEmptyResponse, got", err)
+		}
+	}
+}
+
+func TestBufferedWriter_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		writer := influxdb.NewBufferedWriter(driver, 10, 0)
+		writer.Dedup = true
+		tags := map[string]string{"host": "a"}
+		if err := writer.Write(
+			&influxdb.Point{Measurement: "cpu", Tags: tags, Fields: map[string]interface{}{"value": 1.0}, Time: time.Unix(1, 0)},
+			&influxdb.Point{Measurement: "cpu", Tags: tags, Fields: map[string]interface{}{"value": 2.0}, Time: time.Unix(1, 0)},
+		); err != nil {
+			t.Error(err)
+		} else if len(server.Writes) != 0 {
+			t.Error("Expected no writes before flush, got", len(server.Writes))
+		} else if err := writer.Close(); err != nil {
+			t.Error(err)
+		} else if len(server.Writes) != 1 {
+			t.Error("Expected one write, got", len(server.Writes))
+		} else if lines := server.Writes[0].Lines; len(lines) != 1 {
+			t.Error("Expected one point written, got", lines)
+		} else if strings.Contains(lines[0], "value=1") {
+			t.Error("Expected last value written, got", lines[0])
+		}
+	}
+}
+
+func TestWriteRateLimit_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriverConfig(t, server, v2.Config{Database: "test", WriteRateLimit: 100}); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		points := make([]*influxdb.Point, 50)
+		for i := range points {
+			points[i] = &influxdb.Point{Measurement: "cpu", Fields: map[string]interface{}{"value": float64(i)}, Time: time.Unix(int64(i), 0)}
+		}
+
+		// The first 100 points are written immediately
+		start := time.Now()
+		if err := driver.WriteBatch(append(points, points...)); err != nil {
+			t.Error(err)
+		} else if elapsed := time.Since(start); elapsed > 200*time.Millisecond {
+			t.Error("Expected no delay, got", elapsed)
+		}
+
+		// The next 50 points at 100 points/sec are delayed by about 500ms
+		start = time.Now()
+		if err := driver.WriteBatch(points); err != nil {
+			t.Error(err)
+		} else if elapsed := time.Since(start); elapsed < 400*time.Millisecond {
+			t.Error("Expected delay, got", elapsed)
+		}
+
+		// A write which cannot happen before the deadline returns the context error
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		if err := driver.WriteBatchContext(ctx, append(points, points...)); errors.Is(err, context.DeadlineExceeded) == false {
+			t.Error("Expected DeadlineExceeded, got", err)
+		} else if len(server.Writes) != 2 {
+			t.Error("Expected two writes, got", len(server.Writes))
+		}
+	}
+}
+
+func TestVersionAtLeast_001(t *testing.T) {
+	tests := []struct {
+		version      string
+		major, minor int
+		expected     bool
+	}{
+		{"1.7.10", 1, 7, true},
+		{"1.7.10", 1, 8, false},
+		{"1.8.0", 1, 7, true},
+		{"1.8.0", 1, 8, true},
+		{"1.8.0", 2, 0, false},
+		{"v2.0.4", 1, 8, true},
+		{"v2.0.4", 2, 0, true},
+		{"v2.0.4", 2, 1, false},
+		{"1.8.0~rc1", 1, 8, true},
+		{"unknown", 0, 0, false},
+		{"2", 1, 0, false},
+	}
+	for _, test := range tests {
+		server := NewStubServer()
+		server.Version = test.version
+		if driver := StubDriver(t, server, ""); driver == nil {
+			t.Error("nil driver returned")
+		} else if ok := driver.VersionAtLeast(test.major, test.minor); ok != test.expected {
+			t.Errorf("VersionAtLeast(%v,%v) for %q: expected %v, got %v", test.major, test.minor, test.version, test.expected, ok)
+		} else {
+			driver.Close()
+		}
+		server.Close()
+	}
+}
+
+func TestPartialResult_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		q := influxdb.Select(&influxdb.Measurement{Name: "cpu"})
+		server.Responses[q.String()] = `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","value"],"values":[["2018-01-01T00:00:00Z",1]],"partial":true}]}]}`
+		if results, err := driver.Do(q); err != influxdb.ErrPartialResult {
+			t.Error("Expected ErrPartialResult, got", err)
+		} else if len(results) != 1 || results[0].Partial == false {
+			t.Error("Expected partial results, got", results)
+		}
+
+		server.Responses[q.String()] = `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","value"],"values":[["2018-01-01T00:00:00Z",1]]}]}]}`
+		if results, err := driver.Do(q); err != nil {
+			t.Error(err)
+		} else if len(results) != 1 || results[0].Partial {
+			t.Error("Expected complete results, got", results)
+		}
+	}
+}
+
+func TestSeriesKey_001(t *testing.T) {
+	result := &influxdb.Result{
+		Name: "cpu load",
+		Tags: map[string]string{"region": "eu,west", "host": "server01", "az": "a=1"},
+	}
+	for i := 0; i < 10; i++ {
+		if keys := result.SortedTagKeys(); strings.Join(keys, ",") != "az,host,region" {
+			t.Error("Unexpected tag keys", keys)
+		} else if key := result.SeriesKey(); key != `cpu\ load,az=a\=1,host=server01,region=eu\,west` {
+			t.Error("Unexpected series key", key)
+		}
+	}
+	if key := (&influxdb.Result{Name: "cpu"}).SeriesKey(); key != "cpu" {
+		t.Error("Unexpected series key", key)
+	}
+}
+
+func TestLineEncoder_001(t *testing.T) {
+	encoder := influxdb.LineEncoder{Unsigned: true}
+	ts := time.Unix(1, 500)
+	tests := []struct {
+		value    interface{}
+		expected string
+	}{
+		{1.5, "cpu value=1.5 1000000500"},
+		{float32(2), "cpu value=2 1000000500"},
+		{int64(-3), "cpu value=-3i 1000000500"},
+		{42, "cpu value=42i 1000000500"},
+		{uint64(18446744073709551615), "cpu value=18446744073709551615u 1000000500"},
+		{true, "cpu value=true 1000000500"},
+		{"say \"hi\"", `cpu value="say \"hi\"" 1000000500`},
+	}
+	for _, test := range tests {
+		point := &influxdb.Point{Measurement: "cpu", Fields: map[string]interface{}{"value": test.value}, Time: ts}
+		if line, err := encoder.Encode(point); err != nil {
+			t.Error(err)
+		} else if line != test.expected {
+			t.Errorf("Expected %q, got %q", test.expected, line)
+		}
+	}
+	point := &influxdb.Point{Measurement: "cpu load", Tags: map[string]string{"host": "a b"}, Fields: map[string]interface{}{"value": 1.0}, Time: ts}
+	if line, err := encoder.Encode(point); err != nil {
+		t.Error(err)
+	} else if line != `cpu\ load,host=a\ b value=1 1000000500` {
+		t.Error("Unexpected line", line)
+	}
+	point = &influxdb.Point{Measurement: "cpu", Fields: map[string]interface{}{"value": []int{1, 2}}, Time: ts}
+	if _, err := encoder.Encode(point); errors.Is(err, influxdb.ErrBadParameter) == false {
+		t.Error("Expected ErrBadParameter, got", err)
+	}
+	point = &influxdb.Point{Measurement: "cpu", Fields: map[string]interface{}{"value": uint(1)}, Time: ts}
+	if _, err := (influxdb.LineEncoder{}).Encode(point); errors.Is(err, influxdb.ErrNotSupported) == false {
+		t.Error("Expected ErrNotSupported, got", err)
+	}
+}
+
+func TestWriteBatch_002(t *testing.T) {
+	for _, version := range []string{"1.7.0", "1.8.0"} {
+		server := NewStubServer()
+		server.Version = version
+		if driver := StubDriver(t, server, "test"); driver == nil {
+			t.Error("nil driver returned")
+		} else {
+			err := driver.WritePoint(&influxdb.Point{Measurement: "cpu", Fields: map[string]interface{}{"value": uint64(1)}, Time: time.Unix(1, 0)})
+			if version == "1.7.0" && errors.Is(err, influxdb.ErrNotSupported) == false {
+				t.Error("Expected ErrNotSupported, got", err)
+			} else if version == "1.8.0" && err != nil {
+				t.Error(err)
+			} else if version == "1.8.0" && server.Writes[0].Lines[0] != "cpu value=1u 1000000000" {
+				t.Error("Unexpected line", server.Writes[0].Lines)
+			}
+			driver.Close()
+		}
+		server.Close()
+	}
+}
+
+func TestQueryRaw_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else if client, ok := driver.(*v2.Client); ok == false {
+		t.Error("Expected *v2.Client")
+	} else {
+		defer driver.Close()
+
+		q := "SELECT * FROM cpu; SELECT * FROM mem"
+		server.Responses[q] = `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","value"],"values":[["2018-01-01T00:00:00Z",1]]}]},{"statement_id":1,"series":[{"name":"mem","columns":["time","value"],"values":[["2018-01-01T00:00:00Z",2]]}],"messages":[{"level":"warning","text":"deprecated"}]}]}`
+		if response, err := client.QueryRaw(q); err != nil {
+			t.Error(err)
+		} else if len(response.Results) != 2 {
+			t.Error("Expected two results, got", response.Results)
+		} else if response.Results[0].Series[0].Name != "cpu" || response.Results[1].Series[0].Name != "mem" {
+			t.Error("Unexpected series", response.Results)
+		} else if len(response.Results[1].Messages) != 1 || response.Results[1].Messages[0].Text != "deprecated" {
+			t.Error("Expected message, got", response.Results[1].Messages)
+		}
+	}
+}
+
+func TestValidatePoint_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		server.Responses["SHOW FIELD KEYS FROM cpu"] = `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["fieldKey","fieldType"],"values":[["value","float"],["host_id","integer"]]}]}]}`
+		if fields, err := driver.ShowFieldKeys("cpu"); err != nil {
+			t.Error(err)
+		} else if len(fields) != 2 || fields["value"] != "float" || fields["host_id"] != "integer" {
+			t.Error("Unexpected fields", fields)
+		}
+
+		// No conflict
+		if err := driver.ValidatePoint(&influxdb.Point{Measurement: "cpu", Fields: map[string]interface{}{"value": 1.0, "new": "x"}}); err != nil {
+			t.Error(err)
+		}
+
+		// Conflict
+		if err := driver.ValidatePoint(&influxdb.Point{Measurement: "cpu", Fields: map[string]interface{}{"value": "1.0"}}); errors.Is(err, influxdb.ErrFieldTypeConflict) == false {
+			t.Error("Expected ErrFieldTypeConflict, got", err)
+		}
+
+		// New measurement
+		if err := driver.ValidatePoint(&influxdb.Point{Measurement: "mem", Fields: map[string]interface{}{"value": "1.0"}}); err != nil {
+			t.Error(err)
+		} else if server.HasQuery("SHOW FIELD KEYS FROM mem") == false {
+			t.Error("Expected SHOW FIELD KEYS query")
+		}
+	}
+}
+
+func TestQueries_033(t *testing.T) {
+	query := influxdb.ShowFieldKeys().Database("test").Measurement(&influxdb.Measurement{Name: "cpu load"})
+	if query.String() != "SHOW FIELD KEYS ON test FROM \"cpu load\"" {
+		t.Errorf("Unexpected query: %v", query.String())
+	}
+}
+
+func TestWriteBatchContext_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		points := []*influxdb.Point{
+			{Measurement: "cpu", Fields: map[string]interface{}{"value": 1.0}, Time: time.Unix(1, 0)},
+		}
+
+		// Cancelled before the write
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if err := driver.WriteBatchContext(ctx, points); errors.Is(err, context.Canceled) == false {
+			t.Error("Expected context.Canceled, got", err)
+		} else if len(server.Writes) != 0 {
+			t.Error("Expected no writes, got", len(server.Writes))
+		}
+
+		// Cancelled during the write
+		server.Handlers["/write"] = func(w http.ResponseWriter, r *http.Request) {
+			// Read the body so that the server notices the client disconnect
+			io.Copy(ioutil.Discard, r.Body)
+			<-r.Context().Done()
+		}
+		ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		if err := driver.WriteBatchContext(ctx, points); errors.Is(err, context.DeadlineExceeded) == false {
+			t.Error("Expected context.DeadlineExceeded, got", err)
+		}
+	}
+}
+
+func TestLineEncoder_002(t *testing.T) {
+	encoder := influxdb.LineEncoder{}
+
+	// Zero time omits the timestamp
+	point := &influxdb.Point{Measurement: "cpu", Fields: map[string]interface{}{"value": 1.0}}
+	if line, err := encoder.Encode(point); err != nil {
+		t.Error(err)
+	} else if line != "cpu value=1" {
+		t.Errorf("Expected no timestamp, got %q", line)
+	}
+
+	// Float32 values are formatted at their own precision
+	point = &influxdb.Point{Measurement: "cpu", Fields: map[string]interface{}{"value": float32(1.1)}}
+	if line, err := encoder.Encode(point); err != nil {
+		t.Error(err)
+	} else if line != "cpu value=1.1" {
+		t.Errorf("Unexpected line %q", line)
+	}
+
+	// Tags are written in key order
+	point = &influxdb.Point{Measurement: "cpu", Tags: map[string]string{"c": "3", "a": "1", "b": "2", "d": "4"}, Fields: map[string]interface{}{"value": 1.0}}
+	for i := 0; i < 10; i++ {
+		if line, err := encoder.Encode(point); err != nil {
+			t.Error(err)
+		} else if line != "cpu,a=1,b=2,c=3,d=4 value=1" {
+			t.Errorf("Unexpected line %q", line)
+		}
+	}
+}
+
+func TestQueryConcurrent_003(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	server.Responses["SELECT * FROM partial"] = `{"results":[{"statement_id":0,"series":[{"name":"partial","columns":["time","value"],"values":[[0,1]],"partial":true}]}]}`
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		queries := []influxdb.Query{
+			influxdb.Select(&influxdb.Measurement{Name: "partial"}),
+		}
+		if results, err := driver.QueryConcurrent(queries, 1); errors.Is(err, influxdb.ErrPartialResult) == false {
+			t.Error("Expected ErrPartialResult, got", err)
+		} else if len(results[0]) != 1 || results[0][0].Partial == false {
+			t.Error("Expected partial results, got", results)
+		}
+	}
+}
+
+func TestBufferedWriter_002(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		// Tag sets which would be equal without escaping are not collapsed
+		writer := influxdb.NewBufferedWriter(driver, 10, time.Hour)
+		writer.Dedup = true
+		if err := writer.Write(
+			&influxdb.Point{Measurement: "cpu", Tags: map[string]string{"a": "1,b=2"}, Fields: map[string]interface{}{"value": 1.0}, Time: time.Unix(1, 0)},
+			&influxdb.Point{Measurement: "cpu", Tags: map[string]string{"a": "1", "b": "2"}, Fields: map[string]interface{}{"value": 2.0}, Time: time.Unix(1, 0)},
+		); err != nil {
+			t.Error(err)
+		} else if err := writer.Close(); err != nil {
+			t.Error(err)
+		} else if len(server.Writes) != 1 || len(server.Writes[0].Lines) != 2 {
+			t.Error("Expected two points written, got", server.Writes)
+		}
+
+		// Close can be called more than once
+		if err := writer.Close(); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestSetDatabase_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, ""); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		if err := driver.SetDatabase("missing"); err != influxdb.ErrDatabaseNotFound {
+			t.Error("Expected ErrDatabaseNotFound, got", err)
+		} else if err := driver.SetDatabase("test"); err != nil {
+			t.Error(err)
+		} else if driver.Database() != "test" {
+			t.Error("Expected database test, got", driver.Database())
+		}
+
+		// No databases on the server
+		server.Responses["SHOW DATABASES"] = `{"results":[{"statement_id":0}]}`
+		if err := driver.SetDatabase("test"); err != influxdb.ErrDatabaseNotFound {
+			t.Error("Expected ErrDatabaseNotFound, got", err)
+		}
+	}
+}
+
+func TestWriteRetentionPolicy_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriverConfig(t, server, v2.Config{Database: "test", WriteRetentionPolicy: "week"}); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		if err := driver.WriteBatch([]*influxdb.Point{
+			{Measurement: "cpu", Fields: map[string]interface{}{"value": 1.0}, Time: time.Unix(1, 0)},
+			{Measurement: "autogen.cpu", Fields: map[string]interface{}{"value": 2.0}, Time: time.Unix(2, 0)},
+			{Measurement: "test..cpu", Fields: map[string]interface{}{"value": 3.0}, Time: time.Unix(3, 0)},
+		}); err != nil {
+			t.Error(err)
+		} else if len(server.Writes) != 3 {
+			t.Error("Expected three writes, got", len(server.Writes))
+		} else if rp := server.Writes[0].Values.Get("rp"); rp != "week" {
+			t.Error("Expected configured rp=week, got", rp)
+		} else if rp := server.Writes[1].Values.Get("rp"); rp != "autogen" {
+			t.Error("Expected rp=autogen, got", rp)
+		} else if rp := server.Writes[2].Values.Get("rp"); rp != "" {
+			t.Error("Expected default rp, got", rp)
+		}
+	}
+}
+
+func TestGetMeasurementsWithTagKeys_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		server.Responses["SHOW MEASUREMENTS; SHOW TAG KEYS"] = `{"results":[` +
+			`{"statement_id":0,"series":[{"name":"measurements","columns":["name"],"values":[["cpu"],["mem"],["events"]]}]},` +
+			`{"statement_id":1,"series":[{"name":"cpu","columns":["tagKey"],"values":[["host"],["region"]]},{"name":"mem","columns":["tagKey"],"values":[["host"]]}]}]}`
+		if measurements, err := driver.GetMeasurementsWithTagKeys(); err != nil {
+			t.Error(err)
+		} else if len(measurements) != 3 {
+			t.Error("Expected three measurements, got", measurements)
+		} else if strings.Join(measurements["cpu"], ",") != "host,region" {
+			t.Error("Unexpected cpu tag keys", measurements["cpu"])
+		} else if strings.Join(measurements["mem"], ",") != "host" {
+			t.Error("Unexpected mem tag keys", measurements["mem"])
+		} else if keys, exists := measurements["events"]; exists == false || keys == nil || len(keys) != 0 {
+			t.Error("Expected empty tag keys for events, got", keys)
+		} else if len(server.Queries) != 2 {
+			t.Error("Expected a single request after SHOW DATABASES, got", server.Queries)
+		}
+	}
+}
+
+func TestQueries_034(t *testing.T) {
+	query := influxdb.ShowTagKeys().Database("test").Measurement(&influxdb.Measurement{Name: "cpu"})
+	if query.String() != "SHOW TAG KEYS ON test FROM cpu" {
+		t.Errorf("Unexpected query: %v", query.String())
+	}
+}
+
+func TestQueries_035(t *testing.T) {
+	query := influxdb.ShowGrants("bob")
+	if query.String() != `SHOW GRANTS FOR "bob"` {
+		t.Errorf("Unexpected query: %v", query.String())
+	}
+}
+
+func TestQueries_036(t *testing.T) {
+	query := influxdb.Select().From("cpu", "mem usage")
+	if query.String() != `SELECT * FROM "cpu","mem usage"` {
+		t.Errorf("Unexpected query: %v", query.String())
+	}
+}
+
+func TestQueries_037(t *testing.T) {
+	query := influxdb.Select().FromRegex("^cpu/[0-9]+").Where(influxdb.TagEquals("host", "a"))
+	if query.String() != `SELECT * FROM /^cpu\/[0-9]+/ WHERE host = "a"` {
+		t.Errorf("Unexpected query: %v", query.String())
+	}
+}
+
+func TestQueries_038(t *testing.T) {
+	if query, err := influxdb.Select(&influxdb.Measurement{Name: "cpu"}).GroupBy("host").TZ("America/New_York"); err != nil {
+		t.Error(err)
+	} else if query.String() != `SELECT * FROM cpu GROUP BY host tz('America/New_York')` {
+		t.Errorf("Unexpected query: %v", query.String())
+	}
+	if _, err := influxdb.Select(&influxdb.Measurement{Name: "cpu"}).TZ("Nowhere/Special"); errors.Is(err, influxdb.ErrBadParameter) == false {
+		t.Error("Expected ErrBadParameter, got", err)
+	}
+}
+
+func TestQueries_039(t *testing.T) {
+	cpu := &influxdb.Measurement{Name: "cpu"}
+	if query := influxdb.Select(cpu).GroupByTime(24 * time.Hour); query.String() != "SELECT * FROM cpu GROUP BY time(1d)" {
+		t.Errorf("Unexpected query: %v", query.String())
+	}
+	if query := influxdb.Select(cpu).GroupByTimeOffset(24*time.Hour, 8*time.Hour).GroupBy("host"); query.String() != "SELECT * FROM cpu GROUP BY time(1d,8h),host" {
+		t.Errorf("Unexpected query: %v", query.String())
+	}
+	if query := influxdb.Select(cpu).GroupByTimeOffset(24*time.Hour, -90*time.Minute); query.String() != "SELECT * FROM cpu GROUP BY time(1d,-90m)" {
+		t.Errorf("Unexpected query: %v", query.String())
+	}
+}
+
+func TestSkipPing_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	server.Handlers["/ping"] = func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if driver := StubDriverConfig(t, server, v2.Config{Database: "test", SkipPing: true}); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		if version := driver.Version(); version != "" {
+			t.Error("Expected empty version, got", version)
+		} else if driver.Database() != "test" {
+			t.Error("Expected database test, got", driver.Database())
+		}
+
+		// Version is set after the first successful query
+		server.Lock()
+		delete(server.Handlers, "/ping")
+		server.Unlock()
+		if _, err := driver.Do(influxdb.ShowDatabases()); err != nil {
+			t.Error(err)
+		} else if version := driver.Version(); version != "1.5.2" {
+			t.Error("Expected version 1.5.2, got", version)
+		}
+	}
+}
+
+func TestParseRetentionPolicies_001(t *testing.T) {
+	var response struct {
+		Columns []string        `json:"columns"`
+		Values  [][]interface{} `json:"values"`
+	}
+	responses := []string{
+		`{"columns":["name","duration","shardGroupDuration","replicaN","default"],"values":[["autogen","0s","168h0m0s",1,true],["week","168h0m0s","24h0m0s",1,false]]}`,
+		`{"columns":["name","duration","replicaN","default"],"values":[["autogen","0s",1,1],["week","168h0m0s",1,0]]}`,
+		`{"columns":["name","duration","shardGroupDuration","replicaN","default"],"values":[["autogen","0s","168h0m0s",1,"true"],["week","168h0m0s","24h0m0s",1,"false"]]}`,
+	}
+	for _, body := range responses {
+		decoder := json.NewDecoder(strings.NewReader(body))
+		decoder.UseNumber()
+		if err := decoder.Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		result := &influxdb.Result{Columns: response.Columns, Values: response.Values}
+		if policies, err := result.ParseRetentionPolicies(); err != nil {
+			t.Error(body, err)
+		} else if len(policies) != 2 {
+			t.Error("Expected two policies, got", policies)
+		} else if policies["autogen"].Default == false || policies["week"].Default {
+			t.Error("Unexpected default flags for", body)
+		} else if policies["week"].Duration != 7*24*time.Hour {
+			t.Error("Unexpected duration", policies["week"].Duration)
+		} else if strings.Contains(body, "shardGroupDuration") && policies["week"].ShardGroupDuration != 24*time.Hour {
+			t.Error("Unexpected shard group duration", policies["week"].ShardGroupDuration)
+		}
+	}
+}
+
+func TestPredicates_001(t *testing.T) {
+	where := influxdb.And(influxdb.Equals("a", 1), influxdb.Or(influxdb.Equals("b", "x"), influxdb.NotEquals("c", "y")))
+	if where.String() != "(a = 1 AND (b = 'x' OR c != 'y'))" {
+		t.Error("Unexpected predicate", where.String())
+	}
+	query := influxdb.Select(&influxdb.Measurement{Name: "cpu"}).Where(where)
+	if query.String() != "SELECT * FROM cpu WHERE (a = 1 AND (b = 'x' OR c != 'y'))" {
+		t.Error("Unexpected query", query.String())
+	}
+	tests := map[string]influxdb.Predicate{
+		"value > 1.5":                        influxdb.GreaterThan("value", 1.5),
+		"\"my field\" < 10":                  influxdb.LessThan("my field", 10),
+		"ok = true":                          influxdb.Equals("ok", true),
+		"name = 'it\\'s'":                    influxdb.Equals("name", "it's"),
+		"time > '2018-01-01T00:00:00Z'":      influxdb.GreaterThan("time", time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)),
+		"(a != 1 OR (b != 'x' AND c = 'y'))": influxdb.Not(where),
+		"value <= 1.5":                       influxdb.Not(influxdb.GreaterThan("value", 1.5)),
+		"host != \"a\"":                      influxdb.Not(influxdb.TagEquals("host", "a")),
+		"(host != \"a\" AND host != \"b\")":  influxdb.Not(influxdb.TagEquals("host", "a", "b")),
+		"host !~ /^a/":                       influxdb.Not(influxdb.TagMatches("host", "^a")),
+		"NOT (mean(value))":                  influxdb.Not(influxdb.Mean("value")),
+	}
+	for expected, predicate := range tests {
+		if predicate.String() != expected {
+			t.Errorf("Expected %v, got %v", expected, predicate.String())
+		}
+	}
+}
+
+func TestCopyDatabase_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, ""); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		if err := driver.CopyDatabase("src", "dst", []string{"cpu", "mem"}); err != nil {
+			t.Error(err)
+		} else if server.HasQuery("SELECT * INTO dst..cpu FROM src..cpu GROUP BY *") == false {
+			t.Error("Expected cpu copy, got", server.Queries)
+		} else if server.HasQuery("SELECT * INTO dst..mem FROM src..mem GROUP BY *") == false {
+			t.Error("Expected mem copy, got", server.Queries)
+		}
+
+		// Copy all measurements
+		server.Responses["SHOW MEASUREMENTS ON src"] = `{"results":[{"statement_id":0,"series":[{"name":"measurements","columns":["name"],"values":[["disk"]]}]}]}`
+		if err := driver.CopyDatabase("src", "dst", nil); err != nil {
+			t.Error(err)
+		} else if server.HasQuery("SELECT * INTO dst..disk FROM src..disk GROUP BY *") == false {
+			t.Error("Expected disk copy, got", server.Queries)
+		}
+	}
+}
+
+func TestWriteConsistency_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriverConfig(t, server, v2.Config{Database: "test", WriteConsistency: "quorum"}); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		if err := driver.WritePoint(&influxdb.Point{Measurement: "cpu", Fields: map[string]interface{}{"value": 1.0}}); err != nil {
+			t.Error(err)
+		} else if len(server.Writes) != 1 {
+			t.Error("Expected one write, got", len(server.Writes))
+		} else if consistency := server.Writes[0].Values.Get("consistency"); consistency != "quorum" {
+			t.Error("Expected consistency=quorum, got", consistency)
+		}
+	}
+	if log, err := gopi.Open(logger.Config{}, nil); err != nil {
+		t.Error(err)
+	} else if _, err := gopi.Open(v2.Config{Host: "localhost", WriteConsistency: "most"}, log.(gopi.Logger)); errors.Is(err, influxdb.ErrBadParameter) == false {
+		t.Error("Expected ErrBadParameter for invalid consistency, got", err)
+	}
+}
+
+func TestPointBuilder_001(t *testing.T) {
+	builder := influxdb.NewPointBuilder("cpu")
+	defer builder.Release()
+	encoder := influxdb.LineEncoder{}
+	ts := time.Unix(1, 0)
+	if line, err := encoder.Encode(builder.Tag("host", "a").Field("value", 1.5).Time(ts).Build()); err != nil {
+		t.Error(err)
+	} else if line != "cpu,host=a value=1.5 1000000000" {
+		t.Error("Unexpected line:", line)
+	} else if line, err := encoder.Encode(builder.Reset().Field("value", 2.0).Build()); err != nil {
+		t.Error(err)
+	} else if line != "cpu value=2" {
+		t.Error("Expected tags and time cleared on Reset, got:", line)
+	}
+	builder.Build().TimeUnixNano = 1
+	if line, err := encoder.Encode(builder.Reset().Field("value", 3.0).Build()); err != nil {
+		t.Error(err)
+	} else if line != "cpu value=3" {
+		t.Error("Expected TimeUnixNano cleared on Reset, got:", line)
+	}
+}
+
+var benchmarkPoint *influxdb.Point
+
+func BenchmarkPointBuilder_001(b *testing.B) {
+	ts := time.Unix(1, 0)
+	b.Run("Literal", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			benchmarkPoint = &influxdb.Point{
+				Measurement: "cpu",
+				Tags:        map[string]string{"host": "a", "region": "eu"},
+				Fields:      map[string]interface{}{"value": i, "idle": true},
+				Time:        ts,
+			}
+		}
+	})
+	b.Run("Builder", func(b *testing.B) {
+		b.ReportAllocs()
+		builder := influxdb.NewPointBuilder("cpu")
+		defer builder.Release()
+		for i := 0; i < b.N; i++ {
+			benchmarkPoint = builder.Reset().Tag("host", "a").Tag("region", "eu").Field("value", i).Field("idle", true).Time(ts).Build()
+		}
+	})
+}
+
+func TestLineEncoder_003(t *testing.T) {
+	point := &influxdb.Point{
+		Measurement: "cpu",
+		Fields:      map[string]interface{}{"user": 1.0, "idle": 2.0, "system": 3.0, "nice": 4.0, "steal": 5.0},
+		FieldOrder:  []string{"user", "system"},
+		Time:        time.Unix(1, 0),
+	}
+	encoder := influxdb.LineEncoder{}
+	if line, err := encoder.Encode(point); err != nil {
+		t.Error(err)
+	} else if line != "cpu idle=2,nice=4,steal=5,system=3,user=1 1000000000" {
+		t.Error("Expected fields in key order, got:", line)
+	} else {
+		for i := 0; i < 100; i++ {
+			if line_, err := encoder.Encode(point); err != nil {
+				t.Error(err)
+			} else if line_ != line {
+				t.Fatalf("Expected identical output, got %v and %v", line, line_)
+			}
+		}
+	}
+	encoder.PreserveFieldOrder = true
+	if line, err := encoder.Encode(point); err != nil {
+		t.Error(err)
+	} else if line != "cpu user=1,system=3,idle=2,nice=4,steal=5 1000000000" {
+		t.Error("Expected fields in insertion order, got:", line)
+	}
+}
+
+func TestLineEncoder_004(t *testing.T) {
+	// Nanosecond timestamps beyond float64 precision are written exactly
+	point := &influxdb.Point{
+		Measurement:  "cpu",
+		Fields:       map[string]interface{}{"value": 1.0},
+		Time:         time.Unix(1, 0),
+		TimeUnixNano: 1577836800123456789,
+	}
+	encoder := influxdb.LineEncoder{}
+	if line, err := encoder.Encode(point); err != nil {
+		t.Error(err)
+	} else if line != "cpu value=1 1577836800123456789" {
+		t.Error("Expected nanosecond timestamp, got:", line)
+	}
+	point.TimeUnixNano = 0
+	if line, err := encoder.Encode(point); err != nil {
+		t.Error(err)
+	} else if line != "cpu value=1 1000000000" {
+		t.Error("Expected timestamp from Time, got:", line)
+	}
+}
+
+func TestValidateLineProtocol_001(t *testing.T) {
+	valid := []string{
+		"cpu value=1",
+		"cpu,host=a,region=eu value=1.5,count=2i,total=3u,up=true,name=\"a b\" 1000000000",
+		"cpu\\ load,host\\,name=a\\ b value=-1e10 -1",
+		"cpu name=\"quoted \\\" and, comma=\",up=F",
+		"# comment",
+		"",
+	}
+	for _, line := range valid {
+		if err := influxdb.ValidateLineProtocol(line); err != nil {
+			t.Errorf("Expected %q to be valid: %v", line, err)
+		}
+	}
+	invalid := []string{
+		"cpu",
+		"cpu ",
+		",host=a value=1",
+		"cpu,host value=1",
+		"cpu,host= value=1",
+		"cpu value",
+		"cpu =1",
+		"cpu value=1x",
+		"cpu value=1.5i",
+		"cpu value=-1u",
+		"cpu value=yes",
+		"cpu value=NaN",
+		"cpu value=\"unterminated",
+		"cpu value=\"escaped\\\"",
+		"cpu value=1 not_a_time",
+		"cpu value=1 1 2",
+		"cpu value=1\\",
+	}
+	for _, line := range invalid {
+		if err := influxdb.ValidateLineProtocol(line); errors.Is(err, influxdb.ErrBadParameter) == false {
+			t.Errorf("Expected %q to be invalid, got %v", line, err)
+		}
+	}
+}
+
+func TestShowGrants_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		users := `{"statement_id":0,"series":[{"columns":["user","admin"],"values":[["bob",false],["root",true]]}]}`
+		server.Responses[`SHOW USERS; SHOW GRANTS FOR "bob"`] = `{"results":[` + users + `,` +
+			`{"statement_id":1,"series":[{"columns":["database","privilege"],"values":[["metrics","READ"],["events","WRITE"],["test","ALL PRIVILEGES"]]}]}]}`
+		server.Responses[`SHOW USERS; SHOW GRANTS FOR "root"`] = `{"results":[` + users + `,{"statement_id":1}]}`
+		if grants, err := driver.ShowGrants("bob"); err != nil {
+			t.Error(err)
+		} else if len(grants) != 3 {
+			t.Error("Expected three grants, got", grants)
+		} else if grants[0] != (influxdb.Grant{Database: "metrics", Privilege: "READ"}) {
+			t.Error("Unexpected grant", grants[0])
+		} else if grants[2] != (influxdb.Grant{Database: "test", Privilege: "ALL PRIVILEGES"}) {
+			t.Error("Unexpected grant", grants[2])
+		}
+		if grants, err := driver.ShowGrants("root"); err != nil {
+			t.Error(err)
+		} else if len(grants) != 1 || grants[0] != (influxdb.Grant{Privilege: "ALL PRIVILEGES"}) {
+			t.Error("Expected all privileges for admin user, got", grants)
+		}
+	}
+}
+
+func TestTail_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		// Return two rows, then one row, then no rows
+		responses := []string{
+			`{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","value"],"values":[["2030-01-01T00:00:01Z",1],["2030-01-01T00:00:02Z",2]]}]}]}`,
+			`{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","value"],"values":[["2030-01-01T00:00:03Z",3]]}]}]}`,
+		}
+		server.Lock()
+		server.Queries = nil
+		server.Unlock()
+		server.Handlers["/query"] = func(w http.ResponseWriter, r *http.Request) {
+			server.Lock()
+			server.Queries = append(server.Queries, r.FormValue("q"))
+			response := `{"results":[{"statement_id":0}]}`
+			if len(responses) > 0 {
+				response, responses = responses[0], responses[1:]
+			}
+			server.Unlock()
+			w.Write([]byte(response))
+		}
+		rows := make(chan int, 10)
+		if stop, err := driver.Tail("cpu", 10*time.Millisecond, func(r *influxdb.Result) error {
+			rows <- len(r.Values)
+			return nil
+		}); err != nil {
+			t.Error(err)
+		} else {
+			if n := <-rows; n != 2 {
+				t.Error("Expected two rows, got", n)
+			} else if n := <-rows; n != 1 {
+				t.Error("Expected one row, got", n)
+			}
+			// Wait for the next poll before stopping
+			var queries []string
+			for i := 0; i < 100 && len(queries) < 3; i++ {
+				time.Sleep(10 * time.Millisecond)
+				server.Lock()
+				queries = server.Queries
+				server.Unlock()
+			}
+			stop()
+			stop()
+			if len(queries) < 3 {
+				t.Error("Expected at least three queries, got", queries)
+			} else if queries[1] != `SELECT * FROM cpu WHERE time > '2030-01-01T00:00:02Z'` {
+				t.Error("Unexpected query", queries[1])
+			} else if queries[2] != `SELECT * FROM cpu WHERE time > '2030-01-01T00:00:03Z'` {
+				t.Error("Unexpected query", queries[2])
+			}
+		}
+	}
+}
+
+func TestWhereTagRegex_001(t *testing.T) {
+	tests := map[string]string{
+		"^/var/log":   `path =~ /^\/var\/log/`,
+		`^\/var\/log`: `path =~ /^\/var\/log/`,
+		`a\\/b`:       `path =~ /a\\\/b/`,
+		"^a":          `path =~ /^a/`,
+	}
+	for pattern, expected := range tests {
+		if p, err := influxdb.WhereTagRegex("path", pattern); err != nil {
+			t.Error(err)
+		} else if p.String() != expected {
+			t.Errorf("For %v, expected %v, got %v", pattern, expected, p.String())
+		}
+	}
+	if p, err := influxdb.WhereTagRegex("path", "/tmp"); err != nil {
+		t.Error(err)
+	} else if p := influxdb.Not(p); p.String() != `path !~ /\/tmp/` {
+		t.Error("Unexpected negation", p.String())
+	}
+	if _, err := influxdb.WhereTagRegex("path", "a(b"); errors.Is(err, influxdb.ErrBadParameter) == false {
+		t.Error("Expected ErrBadParameter for invalid pattern, got", err)
+	}
+}
+
+func TestDropAllSeriesInMeasurement_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		if err := driver.DropAllSeriesInMeasurement("cpu", ""); err != influxdb.ErrConfirmationRequired {
+			t.Error("Expected ErrConfirmationRequired, got", err)
+		} else if server.HasQuery("DROP SERIES FROM cpu") {
+			t.Error("Unexpected query without confirmation")
+		} else if err := driver.DropAllSeriesInMeasurement("cpu", influxdb.ConfirmDropAllSeries); err != nil {
+			t.Error(err)
+		} else if server.HasQuery("DROP SERIES FROM cpu") == false {
+			t.Error("Expected DROP SERIES FROM cpu, got", server.Queries)
+		}
+	}
+}
+
+func TestInferColumnTypes_001(t *testing.T) {
+	result := &influxdb.Result{
+		Name:    "cpu",
+		Columns: []string{"time", "host", "value", "count", "idle", "mixed", "empty"},
+		Values: [][]interface{}{
+			{"2018-01-01T00:00:00Z", "a", json.Number("1"), json.Number("10"), true, json.Number("1"), nil},
+			{"2018-01-01T00:01:00Z", nil, json.Number("1.5"), json.Number("11"), false, "x", nil},
+			{"2018-01-01T00:02:00.5Z", "b", nil, json.Number("-3"), nil, true, nil},
+		},
+	}
+	expected := []string{"time", "string", "float", "integer", "boolean", "string", ""}
+	if types := result.InferColumnTypes(); strings.Join(types, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v, got %v", expected, types)
+	} else if types2 := result.InferColumnTypes(); &types2[0] != &types[0] {
+		t.Error("Expected cached types")
+	}
+}
+
+func TestExportCursor_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		page := func(times ...string) string {
+			values := make([]string, len(times))
+			for i, ts := range times {
+				values[i] = `["` + ts + `",` + strconv.Itoa(i) + `]`
+			}
+			return `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","value"],"values":[` + strings.Join(values, ",") + `]}]}]}`
+		}
+		server.Responses["SELECT * FROM cpu LIMIT 2"] = page("2018-01-01T00:00:01Z", "2018-01-01T00:00:02Z")
+		server.Responses["SELECT * FROM cpu WHERE time >= '2018-01-01T00:00:02Z' LIMIT 2 OFFSET 1"] = page("2018-01-01T00:00:02Z", "2018-01-01T00:00:03Z")
+		server.Responses["SELECT * FROM cpu WHERE time >= '2018-01-01T00:00:03Z' LIMIT 2 OFFSET 1"] = page("2018-01-01T00:00:04Z")
+
+		var position string
+		if cursor, err := driver.ExportCursor("cpu", 2); err != nil {
+			t.Error(err)
+		} else if r, ok, err := cursor.Next(); err != nil || ok == false || len(r.Values) != 2 {
+			t.Error("Unexpected first page", r, ok, err)
+		} else if position = cursor.Position(); position != "2018-01-01T00:00:02Z#1" {
+			t.Error("Unexpected position", position)
+		}
+
+		// Resume from the position with a new cursor
+		rows := 0
+		if cursor, err := driver.ExportCursor("cpu", 2); err != nil {
+			t.Error(err)
+		} else if err := cursor.Seek(position); err != nil {
+			t.Error(err)
+		} else {
+			for {
+				if r, ok, err := cursor.Next(); err != nil {
+					t.Fatal(err)
+				} else if ok == false {
+					break
+				} else {
+					rows += len(r.Values)
+				}
+			}
+			if rows != 3 {
+				t.Error("Expected three rows after resuming, got", rows)
+			} else if position := cursor.Position(); position != "2018-01-01T00:00:04Z#1" {
+				t.Error("Unexpected position", position)
+			} else if err := cursor.Seek("bad"); errors.Is(err, influxdb.ErrBadParameter) == false {
+				t.Error("Expected ErrBadParameter, got", err)
+			}
+		}
+	}
+}
+
+func TestQueryOrEmpty_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		server.Responses["SELECT * FROM cpu"] = `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","value"],"values":[["2018-01-01T00:00:00Z",1]]}]}]}`
+		if r, err := driver.QueryOrEmpty("SELECT * FROM empty"); err != nil {
+			t.Error(err)
+		} else if r == nil {
+			t.Error("Expected non-nil result")
+		} else if len(r.Values) != 0 || r.Values == nil {
+			t.Error("Expected zero rows, got", r.Values)
+		} else if r, err := driver.QueryOrEmpty("SELECT * FROM cpu"); err != nil {
+			t.Error(err)
+		} else if len(r.Values) != 1 || strings.Join(r.Columns, ",") != "time,value" {
+			t.Error("Unexpected result", r)
+		}
+	}
+}
+
+func TestWriteBatch_003(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		point := func(measurement string, value float64) *influxdb.Point {
+			return &influxdb.Point{Measurement: measurement, Fields: map[string]interface{}{"value": value}, Time: time.Unix(int64(value), 0)}
+		}
+		if err := driver.WriteBatch([]*influxdb.Point{
+			point("cpu", 1), point("mem", 2), point("cpu", 3), point("disk", 4), point("mem", 5), point("week.cpu", 6),
+		}); err != nil {
+			t.Error(err)
+		} else if len(server.Writes) != 2 {
+			t.Error("Expected two writes, got", len(server.Writes))
+		} else if lines := strings.Join(server.Writes[0].Lines, "\n"); lines != strings.Join([]string{
+			"cpu value=1 1000000000",
+			"cpu value=3 3000000000",
+			"mem value=2 2000000000",
+			"mem value=5 5000000000",
+			"disk value=4 4000000000",
+		}, "\n") {
+			t.Error("Unexpected grouping:", server.Writes[0].Lines)
+		} else if server.Writes[1].Values.Get("rp") != "week" || len(server.Writes[1].Lines) != 1 || server.Writes[1].Lines[0] != "cpu value=6 6000000000" {
+			t.Error("Unexpected write for retention policy:", server.Writes[1])
+		}
+	}
+}
+
+func TestExplainQuery_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		server.Responses["EXPLAIN SELECT mean(value) FROM cpu"] = `{"results":[{"statement_id":0,"series":[{"columns":["QUERY PLAN"],"values":[` +
+			`["EXPRESSION: mean(value::float)"],["NUMBER OF SHARDS: 2"],["NUMBER OF SERIES: 4"],["CACHED VALUES: 0"],["NUMBER OF FILES: 8"],["NUMBER OF BLOCKS: 12"],["SIZE OF BLOCKS: 4096"]]}]}]}`
+		server.Responses["EXPLAIN ANALYZE SELECT mean(value) FROM cpu"] = `{"results":[{"statement_id":0,"error":"error parsing query: found EXPLAIN, expected SELECT, DELETE, SHOW, CREATE, DROP, GRANT, REVOKE, ALTER, SET, KILL at line 1, char 1"}]}`
+		if plan, err := driver.ExplainQuery("SELECT mean(value) FROM cpu"); err != nil {
+			t.Error(err)
+		} else if len(plan.Columns) != 1 || plan.Columns[0] != "QUERY PLAN" {
+			t.Error("Unexpected columns", plan.Columns)
+		} else if len(plan.Values) != 7 || plan.Values[1][0] != "NUMBER OF SHARDS: 2" {
+			t.Error("Unexpected plan", plan.Values)
+		}
+		if _, err := driver.AnalyzeQuery("SELECT mean(value) FROM cpu"); errors.Is(err, influxdb.ErrNotSupported) == false {
+			t.Error("Expected ErrNotSupported, got", err)
+		}
+	}
+}
+
+func TestExportCSV_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		var values url.Values
+		server.Handlers["/query"] = func(w http.ResponseWriter, r *http.Request) {
+			values = r.URL.Query()
+			w.Write([]byte(`{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","host","value"],"values":[["2018-01-01T00:00:00Z","a",1],["2018-01-01T00:00:01Z","b,c",2]],"partial":true}],"partial":true}]}` + "\n"))
+			w.Write([]byte(`{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","host","value"],"values":[["2018-01-01T00:00:02Z",null,3]]}]}]}` + "\n"))
+		}
+		buf := new(strings.Builder)
+		if err := driver.ExportCSV("SELECT * FROM cpu", buf, 2); err != nil {
+			t.Error(err)
+		} else if values.Get("chunked") != "true" || values.Get("chunk_size") != "2" {
+			t.Error("Expected chunked query, got", values)
+		} else if buf.String() != "time,host,value\n"+
+			"2018-01-01T00:00:00Z,a,1\n"+
+			"2018-01-01T00:00:01Z,\"b,c\",2\n"+
+			"2018-01-01T00:00:02Z,,3\n" {
+			t.Error("Unexpected CSV:", buf.String())
+		}
+	}
+}
+
+func TestMeasurementSummary_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		statement := "SHOW FIELD KEYS FROM cpu; SHOW SERIES FROM cpu; SELECT count(*) FROM cpu; SELECT * FROM cpu LIMIT 1; SELECT * FROM cpu ORDER BY time DESC LIMIT 1"
+		server.Responses[statement] = `{"results":[` +
+			`{"statement_id":0,"series":[{"name":"cpu","columns":["fieldKey","fieldType"],"values":[["idle","float"],["user","float"]]}]},` +
+			`{"statement_id":1,"series":[{"columns":["key"],"values":[["cpu,host=a"],["cpu,host=b"],["cpu,host=c"]]}]},` +
+			`{"statement_id":2,"series":[{"name":"cpu","columns":["time","count_idle","count_user"],"values":[["1970-01-01T00:00:00Z",120,118]]}]},` +
+			`{"statement_id":3,"series":[{"name":"cpu","columns":["time","host","idle","user"],"values":[["2018-01-01T00:00:00Z","a",1,2]]}]},` +
+			`{"statement_id":4,"series":[{"name":"cpu","columns":["time","host","idle","user"],"values":[["2018-01-02T00:00:00Z","c",3,4]]}]}]}`
+		server.Responses[strings.Replace(statement, "cpu", "empty", -1)] = `{"results":[{"statement_id":0},{"statement_id":1},{"statement_id":2},{"statement_id":3},{"statement_id":4}]}`
+		if summary, err := driver.MeasurementSummary("cpu"); err != nil {
+			t.Error(err)
+		} else if summary.Points != 120 || summary.Series != 3 {
+			t.Error("Unexpected counts", summary)
+		} else if summary.First.Equal(time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)) == false || summary.Last.Equal(time.Date(2018, 1, 2, 0, 0, 0, 0, time.UTC)) == false {
+			t.Error("Unexpected timestamps", summary)
+		} else if len(summary.Fields) != 2 || summary.Fields["idle"] != "float" {
+			t.Error("Unexpected fields", summary.Fields)
+		}
+		if _, err := driver.MeasurementSummary("empty"); errors.Is(err, influxdb.ErrNotFound) == false {
+			t.Error("Expected ErrNotFound, got", err)
+		}
+	}
+}
+
+func TestHeaders_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	headers := make(chan http.Header, 10)
+	record := func(next func(w http.ResponseWriter, r *http.Request)) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			headers <- r.Header
+			next(w, r)
+		}
+	}
+	server.Handlers["/ping"] = record(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Influxdb-Version", "1.8.10")
+		w.WriteHeader(http.StatusNoContent)
+	})
+	if driver := StubDriverConfig(t, server, v2.Config{
+		Username: "admin",
+		Password: "secret",
+		Headers:  map[string]string{"X-Request-ID": "abc123", "Authorization": "Bearer token"},
+	}); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		if header := <-headers; header.Get("X-Request-ID") != "abc123" {
+			t.Error("Expected X-Request-ID on ping, got", header)
+		} else if driver.Version() != "1.8.10" {
+			t.Error("Expected version from ping, got", driver.Version())
+		}
+		server.Handlers["SHOW MEASUREMENTS"] = record(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"results":[{"statement_id":0}]}`))
+		})
+		if _, err := driver.GetMeasurements(""); err != nil {
+			t.Error(err)
+		} else if header := <-headers; header.Get("X-Request-ID") != "abc123" {
+			t.Error("Expected X-Request-ID on query, got", header)
+		}
+		server.Handlers["/write"] = record(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		})
+		if err := driver.WritePoint(&influxdb.Point{Measurement: "test..cpu", Fields: map[string]interface{}{"value": 1.0}}); err != nil {
+			t.Error(err)
+		} else if header := <-headers; header.Get("X-Request-ID") != "abc123" {
+			t.Error("Expected X-Request-ID on write, got", header)
+		} else if user, password, ok := (&http.Request{Header: header}).BasicAuth(); ok == false || user != "admin" || password != "secret" {
+			t.Error("Expected basic auth to take precedence, got", header.Get("Authorization"))
+		}
+	}
+}
+
+func TestBufferedWriter_003(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		// Points written just before Close are persisted
+		writer := influxdb.NewBufferedWriter(driver, 100, 5*time.Millisecond)
+		writer.DrainTimeout = time.Second
+		for i := 0; i < 10; i++ {
+			if err := writer.Write(&influxdb.Point{Measurement: "cpu", Fields: map[string]interface{}{"value": float64(i)}, Time: time.Unix(int64(i), 0)}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := writer.Close(); err != nil {
+			t.Error(err)
+		}
+		server.Lock()
+		points := 0
+		for _, write := range server.Writes {
+			points += len(write.Lines)
+		}
+		server.Unlock()
+		if points != 10 {
+			t.Error("Expected ten points written, got", points)
+		} else if err := writer.Write(&influxdb.Point{Measurement: "cpu", Fields: map[string]interface{}{"value": 1.0}}); err != influxdb.ErrWriterClosed {
+			t.Error("Expected ErrWriterClosed, got", err)
+		}
+
+		// Close returns when the drain timeout elapses
+		release := make(chan struct{})
+		server.Handlers["/write"] = func(w http.ResponseWriter, r *http.Request) {
+			io.Copy(ioutil.Discard, r.Body)
+			<-release
+			w.WriteHeader(http.StatusNoContent)
+		}
+		writer = influxdb.NewBufferedWriter(driver, 100, 0)
+		writer.DrainTimeout = 20 * time.Millisecond
+		if err := writer.Write(&influxdb.Point{Measurement: "cpu", Fields: map[string]interface{}{"value": 1.0}}); err != nil {
+			t.Error(err)
+		} else if err := writer.Close(); errors.Is(err, context.DeadlineExceeded) == false {
+			t.Error("Expected context.DeadlineExceeded, got", err)
+		}
+
+		// The abandoned flush completes before the client is closed
+		close(release)
+		if err := writer.Wait(); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestConfigValidate_001(t *testing.T) {
+	tests := []v2.Config{
+		{},
+		{Host: " "},
+		{Host: "localhost", Password: "secret"},
+		{Host: "localhost", Precision: "y"},
+		{Host: "localhost", WriteConsistency: "most"},
+		{Host: "localhost", Timeout: -time.Second},
+		{Host: "localhost", WriteRateLimit: -1},
+	}
+	for _, config := range tests {
+		if err := config.Validate(); errors.Is(err, influxdb.ErrBadParameter) == false {
+			t.Errorf("Expected ErrBadParameter for %+v, got %v", config, err)
+		}
+	}
+	if err := (v2.Config{Host: "localhost", Username: "admin", Password: "secret", Precision: "u", Timeout: time.Second}).Validate(); err != nil {
+		t.Error(err)
+	}
+	if log, err := gopi.Open(logger.Config{}, nil); err != nil {
+		t.Error(err)
+	} else if _, err := gopi.Open(v2.Config{}, log.(gopi.Logger)); errors.Is(err, influxdb.ErrBadParameter) == false {
+		t.Error("Expected Open to return ErrBadParameter, got", err)
+	}
+}
+
+func TestQueryOn_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		var database string
+		server.Handlers["SELECT * FROM cpu"] = func(w http.ResponseWriter, r *http.Request) {
+			database = r.FormValue("db")
+			w.Write([]byte(`{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","value"],"values":[["2018-01-01T00:00:00Z",1]]}]}]}`))
+		}
+		if _, err := driver.QueryOn("other", influxdb.Select(&influxdb.Measurement{Name: "cpu"})); err != nil {
+			t.Error(err)
+		} else if database != "other" {
+			t.Error("Expected query on other, got", database)
+		} else if driver.Database() != "test" {
+			t.Error("Expected current database unchanged, got", driver.Database())
+		}
+	}
+}
+
+func TestWriteVerified_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		// The point is visible on the second poll
+		polls := 0
+		server.Handlers["SELECT * FROM cpu WHERE time = '1970-01-01T00:00:01Z' AND host = 'a' LIMIT 1"] = func(w http.ResponseWriter, r *http.Request) {
+			if polls++; polls < 2 {
+				w.Write([]byte(`{"results":[{"statement_id":0}]}`))
+			} else {
+				w.Write([]byte(`{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","host","value"],"values":[["1970-01-01T00:00:01Z","a",1]]}]}]}`))
+			}
+		}
+		point := &influxdb.Point{Measurement: "cpu", Tags: map[string]string{"host": "a"}, Fields: map[string]interface{}{"value": 1.0}, Time: time.Unix(1, 0)}
+		if err := driver.WriteVerified(point, time.Second); err != nil {
+			t.Error(err)
+		} else if polls != 2 {
+			t.Error("Expected two polls, got", polls)
+		} else if len(server.Writes) != 1 {
+			t.Error("Expected one write, got", len(server.Writes))
+		}
+
+		// The point is never visible
+		point.Tags["host"] = "b"
+		if err := driver.WriteVerified(point, 100*time.Millisecond); errors.Is(err, influxdb.ErrNotFound) == false {
+			t.Error("Expected ErrNotFound, got", err)
+		}
+	}
+}
+
+func TestConfig_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriverConfig(t, server, v2.Config{
+		Database: "test",
+		Username: "admin",
+		Password: "secret",
+		Headers:  map[string]string{"authorization": "Bearer token", "X-Request-ID": "abc123"},
+	}); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		config := driver.(*v2.Client).Config()
+		if driver.Addr() != server.URL+"/" {
+			t.Errorf("Expected %v/, got %v", server.URL, driver.Addr())
+		} else if config.Username != "admin" || config.Database != "test" {
+			t.Error("Unexpected config", config)
+		} else if config.Password == "secret" || config.Password == "" {
+			t.Error("Expected password to be masked, got", config.Password)
+		} else if config.Headers["authorization"] == "Bearer token" {
+			t.Error("Expected authorization header to be masked")
+		} else if config.Headers["X-Request-ID"] != "abc123" {
+			t.Error("Unexpected headers", config.Headers)
+		}
+	}
+}
+
+func TestShowSubscriptions_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		server.Responses["SHOW SUBSCRIPTIONS"] = `{"results":[{"statement_id":0,"series":[` +
+			`{"name":"metrics","columns":["retention_policy","name","mode","destinations"],"values":[["autogen","kapacitor","ANY",["http://kapacitor:9092"]]]},` +
+			`{"name":"events","columns":["retention_policy","name","mode","destinations"],"values":[["week","replica","ALL",["udp://a:9090","udp://b:9090"]]]}]}]}`
+		if subscriptions, err := driver.ShowSubscriptions(); err != nil {
+			t.Error(err)
+		} else if len(subscriptions) != 2 {
+			t.Error("Expected two subscriptions, got", subscriptions)
+		} else if s := subscriptions[0]; s.Database != "metrics" || s.RetentionPolicy != "autogen" || s.Name != "kapacitor" || s.Mode != "ANY" || strings.Join(s.Destinations, ",") != "http://kapacitor:9092" {
+			t.Error("Unexpected subscription", s)
+		} else if s := subscriptions[1]; s.Database != "events" || s.RetentionPolicy != "week" || s.Mode != "ALL" || len(s.Destinations) != 2 || s.Destinations[1] != "udp://b:9090" {
+			t.Error("Unexpected subscription", s)
+		}
+	}
+}
+
+func TestDropMeasurementsMatching_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		server.Responses["SHOW MEASUREMENTS"] = `{"results":[{"statement_id":0,"series":[{"name":"measurements","columns":["name"],"values":[["cpu"],["test_a"],["test_b"],["mem"]]}]}]}`
+		if n, err := driver.WithDryRun(true).DropMeasurementsMatching("^test_"); errors.Is(err, influxdb.ErrDryRun) == false {
+			t.Error("Expected ErrDryRun, got", err)
+		} else if n != 0 || server.HasQuery("DROP MEASUREMENT test_a") {
+			t.Error("Unexpected drop in dry run")
+		} else if err.(*influxdb.DryRunError).Statement != "DROP MEASUREMENT test_a; DROP MEASUREMENT test_b" {
+			t.Error("Unexpected statements", err)
+		}
+		if n, err := driver.DropMeasurementsMatching("^test_"); err != nil {
+			t.Error(err)
+		} else if n != 2 {
+			t.Error("Expected two measurements dropped, got", n)
+		} else if server.HasQuery("DROP MEASUREMENT test_a") == false || server.HasQuery("DROP MEASUREMENT test_b") == false {
+			t.Error("Expected drops, got", server.Queries)
+		} else if server.HasQuery("DROP MEASUREMENT cpu") {
+			t.Error("Unexpected drop of cpu")
+		}
+		if _, err := driver.DropMeasurementsMatching("("); errors.Is(err, influxdb.ErrBadParameter) == false {
+			t.Error("Expected ErrBadParameter, got", err)
+		}
+	}
+}
+
+func TestWithCache_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriverConfig(t, server, v2.Config{Database: "test", CacheInvalidateOnWrite: true}); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		hits := 0
+		server.Handlers["SELECT * FROM cpu"] = func(w http.ResponseWriter, r *http.Request) {
+			hits++
+			w.Write([]byte(`{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","value"],"values":[["1970-01-01T00:00:01Z",1]]}]}]}`))
+		}
+		cached := driver.WithCache(time.Minute)
+		query := influxdb.Select(&influxdb.Measurement{Name: "cpu"})
+		if r, err := cached.Do(query); err != nil {
+			t.Error(err)
+		} else if r2, err := cached.Do(query); err != nil {
+			t.Error(err)
+		} else if hits != 1 {
+			t.Error("Expected one query to the server, got", hits)
+		} else if len(r2) != 1 || r2[0] != r[0] {
+			t.Error("Expected cached results, got", r2)
+		}
+
+		// The uncached client always queries the server
+		if _, err := driver.Do(query); err != nil {
+			t.Error(err)
+		} else if hits != 2 {
+			t.Error("Expected two queries to the server, got", hits)
+		}
+
+		// Writing to the measurement invalidates the cached query
+		point := &influxdb.Point{Measurement: "cpu", Fields: map[string]interface{}{"value": 2.0}, Time: time.Unix(2, 0)}
+		if err := cached.WritePoint(point); err != nil {
+			t.Error(err)
+		} else if _, err := cached.Do(query); err != nil {
+			t.Error(err)
+		} else if hits != 3 {
+			t.Error("Expected three queries to the server, got", hits)
+		}
+
+		// Entries expire after the ttl
+		expiring := driver.WithCache(10 * time.Millisecond)
+		if _, err := expiring.Do(query); err != nil {
+			t.Error(err)
+		}
+		time.Sleep(20 * time.Millisecond)
+		if _, err := expiring.Do(query); err != nil {
+			t.Error(err)
+		} else if hits != 5 {
+			t.Error("Expected five queries to the server, got", hits)
+		}
+	}
+}
+
+func TestEstimatePointCount_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		// The only measurement in the database uses shard statistics
+		server.Responses["SHOW MEASUREMENTS ON test"] = `{"results":[{"statement_id":0,"series":[{"name":"measurements","columns":["name"],"values":[["cpu"]]}]}]}`
+		server.Responses["SHOW STATS FOR 'shard'"] = `{"results":[{"statement_id":0,"series":[` +
+			`{"name":"shard","tags":{"database":"test","id":"1"},"columns":["diskBytes","writePointsOk"],"values":[[100,40]]},` +
+			`{"name":"shard","tags":{"database":"test","id":"2"},"columns":["diskBytes","writePointsOk"],"values":[[100,2]]},` +
+			`{"name":"shard","tags":{"database":"other","id":"3"},"columns":["diskBytes","writePointsOk"],"values":[[100,1000]]}]}]}`
+		server.Responses["SELECT count(*) FROM cpu"] = `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","count_value","count_other"],"values":[["1970-01-01T00:00:00Z",10,7]]}]}]}`
+		if n, err := driver.EstimatePointCount("cpu"); err != nil {
+			t.Error(err)
+		} else if n != 42 {
+			t.Error("Expected 42 points, got", n)
+		} else if server.HasQuery("SELECT count(*) FROM cpu") {
+			t.Error("Unexpected count query")
+		}
+
+		// Other measurements in the database fall back to counting
+		server.Responses["SHOW MEASUREMENTS ON test"] = `{"results":[{"statement_id":0,"series":[{"name":"measurements","columns":["name"],"values":[["cpu"],["mem"]]}]}]}`
+		if n, err := driver.EstimatePointCount("cpu"); err != nil {
+			t.Error(err)
+		} else if n != 10 {
+			t.Error("Expected 10 points, got", n)
+		} else if server.HasQuery("SELECT count(*) FROM cpu") == false {
+			t.Error("Expected count query, got", server.Queries)
+		}
+	}
+}
+
+func TestMigrateToPolicy_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		server.Responses["SHOW RETENTION POLICIES ON test"] = `{"results":[{"statement_id":0,"series":[{"columns":["name","duration","shardGroupDuration","replicaN","default"],"values":[["autogen","0s","168h0m0s",1,true],["year","8760h0m0s","168h0m0s",1,false]]}]}]}`
+		server.Responses["SELECT * INTO test.year.cpu FROM test.autogen.cpu GROUP BY *"] = `{"results":[{"statement_id":0,"series":[{"name":"result","columns":["time","written"],"values":[["1970-01-01T00:00:00Z",10]]}]}]}`
+		if err := driver.MigrateToPolicy("cpu", "autogen", "year"); err != nil {
+			t.Error(err)
+		} else if server.HasQuery("SELECT * INTO test.year.cpu FROM test.autogen.cpu GROUP BY *") == false {
+			t.Error("Expected migrate statement, got", server.Queries)
+		}
+		if err := driver.MigrateToPolicy("cpu", "autogen", "forever"); errors.Is(err, influxdb.ErrNotFound) == false {
+			t.Error("Expected ErrNotFound, got", err)
+		}
+		if err := driver.MigrateToPolicy("cpu", "year", "year"); errors.Is(err, influxdb.ErrBadParameter) == false {
+			t.Error("Expected ErrBadParameter, got", err)
+		}
+	}
+}
+
+func TestWriteError_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		point := &influxdb.Point{Measurement: "cpu", Fields: map[string]interface{}{"value": 1.0}}
+		server.Handlers["/write"] = func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+		}
+		if err := driver.WritePoint(point); errors.Is(err, influxdb.ErrPayloadTooLarge) == false {
+			t.Error("Expected ErrPayloadTooLarge, got", err)
+		} else if strings.Contains(err.Error(), "reduce the batch size") == false {
+			t.Error("Expected batch size suggestion, got", err)
+		}
+		server.Handlers["/write"] = func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"error":"store is read-only"}`))
+		}
+		if err := driver.WritePoint(point); errors.Is(err, influxdb.ErrReadOnly) == false {
+			t.Error("Expected ErrReadOnly, got", err)
+		}
+		server.Handlers["/write"] = func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"error":"user is not authorized to write to database test"}`))
+		}
+		if err := driver.WritePoint(point); err == nil || errors.Is(err, influxdb.ErrReadOnly) {
+			t.Error("Expected authorization error, got", err)
+		}
+	}
+}
+
+func TestResultEqual_001(t *testing.T) {
+	a := &influxdb.Result{
+		Name:    "cpu",
+		Tags:    map[string]string{"host": "a"},
+		Columns: []string{"time", "value", "count", "up"},
+		Values:  [][]interface{}{{"1970-01-01T00:00:01Z", json.Number("1.5"), json.Number("1"), true}},
+	}
+	b := &influxdb.Result{
+		Name:    "cpu",
+		Tags:    map[string]string{"host": "a"},
+		Columns: []string{"time", "value", "count", "up"},
+		Values:  [][]interface{}{{"1970-01-01T00:00:01Z", 1.5, int64(1), true}},
+	}
+	if a.Equal(b) == false || b.Equal(a) == false {
+		t.Error("Expected results to be equal after numeric normalization")
+	}
+	b.Values[0][2] = 2.0
+	if a.Equal(b) {
+		t.Error("Expected results with different values to be unequal")
+	}
+	b.Values[0][2] = 1.0
+	if a.Equal(b) == false {
+		t.Error("Expected float 1 to equal json.Number 1")
+	}
+	b.Columns = []string{"time", "count", "value", "up"}
+	if a.Equal(b) {
+		t.Error("Expected results with different column order to be unequal")
+	}
+	b.Columns = a.Columns
+	b.Tags = map[string]string{"host": "b"}
+	if a.Equal(b) {
+		t.Error("Expected results with different tags to be unequal")
+	}
+	b.Tags = a.Tags
+	b.Values = append(b.Values, b.Values[0])
+	if a.Equal(b) {
+		t.Error("Expected results with different rows to be unequal")
+	}
+}
+
+func TestRenameTag_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		server.Responses["SHOW TAG KEYS FROM cpu"] = `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["tagKey"],"values":[["host"],["region"]]}]}]}`
+		server.Responses["SHOW FIELD KEYS FROM cpu"] = `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["fieldKey","fieldType"],"values":[["value","float"],["count","integer"]]}]}]}`
+		server.Responses["SELECT * FROM cpu WHERE host =~ /.+/ GROUP BY *"] = `{"results":[{"statement_id":0,"series":[` +
+			`{"name":"cpu","tags":{"host":"a","region":"eu"},"columns":["time","count","value"],"values":[["1970-01-01T00:00:01Z",1,1.5],["1970-01-01T00:00:02Z",2,null]]}]}]}`
+
+		if err := driver.RenameTag("cpu", "host", "hostname", ""); errors.Is(err, influxdb.ErrConfirmationRequired) == false {
+			t.Error("Expected ErrConfirmationRequired, got", err)
+		}
+		if err := driver.RenameTag("cpu", "host", "region", influxdb.ConfirmRenameTag); errors.Is(err, influxdb.ErrAlreadyExists) == false {
+			t.Error("Expected ErrAlreadyExists, got", err)
+		}
+		if err := driver.RenameTag("cpu", "dc", "datacenter", influxdb.ConfirmRenameTag); errors.Is(err, influxdb.ErrNotFound) == false {
+			t.Error("Expected ErrNotFound, got", err)
+		}
+
+		// Dry run does not read, write or drop
+		var dryrun *influxdb.DryRunError
+		if err := driver.WithDryRun(true).RenameTag("cpu", "host", "hostname", influxdb.ConfirmRenameTag); errors.As(err, &dryrun) == false {
+			t.Error("Expected DryRunError, got", err)
+		} else if dryrun.Statement != "DROP SERIES FROM cpu WHERE host =~ /.+/" {
+			t.Error("Unexpected statement", dryrun.Statement)
+		} else if server.HasQuery("SELECT * FROM cpu WHERE host =~ /.+/ GROUP BY *") || len(server.Writes) != 0 {
+			t.Error("Unexpected query or write in dry run")
+		}
+
+		if err := driver.RenameTag("cpu", "host", "hostname", influxdb.ConfirmRenameTag); err != nil {
+			t.Error(err)
+		} else if len(server.Writes) != 1 {
+			t.Error("Expected one write, got", len(server.Writes))
+		} else if lines := server.Writes[0].Lines; len(lines) != 2 {
+			t.Error("Expected two lines, got", lines)
+		} else if lines[0] != "cpu,hostname=a,region=eu count=1i,value=1.5 1000000000" || lines[1] != "cpu,hostname=a,region=eu count=2i 2000000000" {
+			t.Error("Unexpected lines", lines)
+		} else if server.HasQuery("DROP SERIES FROM cpu WHERE host =~ /.+/") == false {
+			t.Error("Expected drop series, got", server.Queries)
+		}
+	}
+}
+
+func TestBuildInfo_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		server.Responses["SHOW DIAGNOSTICS"] = `{"results":[{"statement_id":0,"series":[` +
+			`{"name":"build","columns":["Branch","Build Time","Commit","Version"],"values":[["1.8","2020-01-01T00:00:00Z","abc123","1.8.10"]]},` +
+			`{"name":"runtime","columns":["GOARCH","GOMAXPROCS","GOOS","version"],"values":[["amd64",4,"linux","go1.13.8"]]}]}]}`
+		if info, err := driver.BuildInfo(); err != nil {
+			t.Error(err)
+		} else if info.Version != "1.8.10" || info.Branch != "1.8" || info.Commit != "abc123" || info.BuildTime != "2020-01-01T00:00:00Z" {
+			t.Error("Unexpected build info", info)
+		}
+		server.Responses["SHOW DIAGNOSTICS"] = `{"results":[{"statement_id":0,"series":[{"name":"runtime","columns":["GOOS"],"values":[["linux"]]}]}]}`
+		if _, err := driver.BuildInfo(); errors.Is(err, influxdb.ErrUnexpectedResponse) == false {
+			t.Error("Expected ErrUnexpectedResponse, got", err)
+		}
+	}
+}
+
+func TestDropShard_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		if err := driver.DropShard(0); errors.Is(err, influxdb.ErrBadParameter) == false {
+			t.Error("Expected ErrBadParameter, got", err)
+		} else if err := driver.DropShard(-1); errors.Is(err, influxdb.ErrBadParameter) == false {
+			t.Error("Expected ErrBadParameter, got", err)
+		}
+		if err := driver.WithDryRun(true).DropShard(12); errors.Is(err, influxdb.ErrDryRun) == false {
+			t.Error("Expected ErrDryRun, got", err)
+		} else if server.HasQuery("DROP SHARD 12") {
+			t.Error("Unexpected drop in dry run")
+		}
+		if err := driver.DropShard(12); err != nil {
+			t.Error(err)
+		} else if server.HasQuery("DROP SHARD 12") == false {
+			t.Error("Expected DROP SHARD 12, got", server.Queries)
+		}
+	}
+}
+
+func TestQueryIter_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		server.Responses["SELECT * FROM cpu GROUP BY host"] = `{"results":[{"statement_id":0,"series":[` +
+			`{"name":"cpu","tags":{"host":"a"},"columns":["time","value","count","up","name"],"values":[["1970-01-01T00:00:01Z",1.5,2,true,"x"],["1970-01-01T00:00:02Z",2.5,3,false,"y"]]},` +
+			`{"name":"cpu","tags":{"host":"b"},"columns":["time","value","count","up","name"],"values":[["1970-01-01T00:00:03Z",3.5,4,true,null]]}]}]}`
+		iter, err := driver.QueryIter("SELECT * FROM cpu GROUP BY host")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ts time.Time
+		var value float64
+		var count int64
+		var up bool
+		var name interface{}
+		hosts, total := "", int64(0)
+		for iter.Next() {
+			if err := iter.Scan(&ts, &value, &count, &up, &name); err != nil {
+				t.Error(err)
+			} else {
+				hosts += iter.Result().Tags["host"]
+				total += count
+			}
+		}
+		if err := iter.Err(); err != nil {
+			t.Error(err)
+		} else if hosts != "aab" || total != 9 {
+			t.Error("Unexpected rows", hosts, total)
+		} else if ts.Equal(time.Unix(3, 0)) == false || value != 3.5 || up != true || name != nil {
+			t.Error("Unexpected last row", ts, value, up, name)
+		}
+
+		// Type conversion errors and nulls
+		iter, _ = driver.QueryIter("SELECT * FROM cpu GROUP BY host")
+		var str string
+		if iter.Next() == false {
+			t.Error("Expected a row")
+		} else if err := iter.Scan(&ts, &up, &count, &up, &name); errors.Is(err, influxdb.ErrBadParameter) == false {
+			t.Error("Expected ErrBadParameter, got", err)
+		} else if err := iter.Scan(&ts, &value); errors.Is(err, influxdb.ErrBadParameter) == false {
+			t.Error("Expected ErrBadParameter, got", err)
+		}
+		iter.Next()
+		iter.Next()
+		if err := iter.Scan(&ts, &value, &count, &up, &str); errors.Is(err, influxdb.ErrBadParameter) == false {
+			t.Error("Expected ErrBadParameter for null, got", err)
+		}
+
+		// No data returns no rows
+		if iter, err := driver.QueryIter("SELECT * FROM mem"); err != nil {
+			t.Error(err)
+		} else if iter.Next() {
+			t.Error("Expected no rows")
+		}
+	}
+}
+
+func TestQueryWithMessages_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		server.Responses["SELECT * FROM cpu"] = `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","value"],"values":[["1970-01-01T00:00:01Z",1]]}],"messages":[{"level":"warning","text":"deprecated use of 'SELECT'"}]}]}`
+		if result, messages, err := driver.QueryWithMessages("SELECT * FROM cpu"); err != nil {
+			t.Error(err)
+		} else if len(result.Values) != 1 {
+			t.Error("Unexpected result", result)
+		} else if len(messages) != 1 || messages[0] != "warning: deprecated use of 'SELECT'" {
+			t.Error("Unexpected messages", messages)
+		}
+		if result, messages, err := driver.QueryWithMessages("SELECT * FROM mem"); err != nil {
+			t.Error(err)
+		} else if len(result.Values) != 0 || len(messages) != 0 {
+			t.Error("Unexpected result or messages", result, messages)
+		}
+	}
+}
+
+func TestColumnNames_001(t *testing.T) {
+	result := &influxdb.Result{
+		Name:    "cpu",
+		Columns: []string{"time", "value", "value", "value_2", "value"},
+		Values:  [][]interface{}{{"1970-01-01T00:00:01Z", 1, 2, 3, 4}},
+	}
+	if names := result.ColumnNames(); strings.Join(names, ",") != "time,value,value_3,value_2,value_4" {
+		t.Error("Unexpected column names", names)
+	}
+	if i := result.ColumnIndex("value"); i != 1 {
+		t.Error("Expected value at 1, got", i)
+	} else if i := result.ColumnIndex("value_3"); i != 2 {
+		t.Error("Expected value_3 at 2, got", i)
+	} else if i := result.ColumnIndex("missing"); i != -1 {
+		t.Error("Expected -1, got", i)
+	}
+	if row := result.RowMap(0); len(row) != 5 || row["value"] != 1 || row["value_3"] != 2 || row["value_2"] != 3 || row["value_4"] != 4 {
+		t.Error("Unexpected row map", row)
+	} else if result.RowMap(1) != nil {
+		t.Error("Expected nil for a missing row")
+	}
+
+	// Two columns named value
+	result = &influxdb.Result{Name: "cpu", Columns: []string{"value", "value"}, Values: [][]interface{}{{1, 2}}}
+	if values, err := (influxdb.Results{result}).Column(0, "cpu", "value_2"); err != nil {
+		t.Error(err)
+	} else if len(values) != 1 || values[0] != 2 {
+		t.Error("Unexpected values", values)
+	}
+}
+
+func TestSetupDownsampling_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		server.Queries = nil
+		if err := driver.SetupDownsampling("metrics", "raw", "hourly", time.Hour); err != nil {
+			t.Fatal(err)
+		}
+		expected := []string{
+			"CREATE DATABASE metrics",
+			"CREATE RETENTION POLICY raw ON metrics DURATION 168h0m0s REPLICATION 1 DEFAULT",
+			"CREATE RETENTION POLICY hourly ON metrics DURATION 0s REPLICATION 1",
+			"CREATE CONTINUOUS QUERY cq_hourly ON metrics BEGIN SELECT mean(*) INTO metrics.hourly.:MEASUREMENT FROM metrics.raw./.*/ GROUP BY time(1h),* END",
+		}
+		if len(server.Queries) != len(expected) {
+			t.Fatal("Unexpected statements", server.Queries)
+		}
+		for i, query := range expected {
+			if server.Queries[i] != query {
+				t.Errorf("Statement %v: expected %q, got %q", i, query, server.Queries[i])
+			}
+		}
+		if err := driver.SetupDownsampling("metrics", "raw", "raw", time.Hour); errors.Is(err, influxdb.ErrBadParameter) == false {
+			t.Error("Expected ErrBadParameter, got", err)
+		}
+	}
+}
+
+func TestClassifyError_001(t *testing.T) {
+	tests := []struct {
+		err  error
+		kind influxdb.ErrorKind
+	}{
+		{nil, influxdb.ErrorUnknown},
+		{errors.New(`partial write: field type conflict: input field "value" on measurement "cpu" is type integer, already exists as type float dropped=1`), influxdb.ErrorFieldTypeConflict},
+		{errors.New(`retention policy not found: year`), influxdb.ErrorRetentionPolicyNotFound},
+		{errors.New(`database not found: "metrics"`), influxdb.ErrorDatabaseNotFound},
+		{errors.New(`authorization failed`), influxdb.ErrorAuthFailure},
+		{errors.New(`unable to parse authentication credentials`), influxdb.ErrorAuthFailure},
+		{errors.New(`error authorizing query: user is not authorized to execute statement`), influxdb.ErrorAuthFailure},
+		{errors.Join(errors.New("write failed"), influxdb.ErrFieldTypeConflict), influxdb.ErrorFieldTypeConflict},
+		{influxdb.ErrDatabaseNotFound, influxdb.ErrorDatabaseNotFound},
+		{errors.New(`error parsing query: found EOF`), influxdb.ErrorUnknown},
+	}
+	for _, test := range tests {
+		if kind := influxdb.ClassifyError(test.err); kind != test.kind {
+			t.Errorf("For %v, expected %v, got %v", test.err, test.kind, kind)
+		}
+	}
+}
+
+func TestSeriesCardinalityExact_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		if query := influxdb.ShowSeriesCardinality(true).Measurement(&influxdb.Measurement{Name: "cpu load"}); query.String() != `SHOW SERIES EXACT CARDINALITY FROM "cpu load"` {
+			t.Errorf("Unexpected query: %v", query)
+		}
+		server.Responses["SHOW SERIES EXACT CARDINALITY FROM cpu"] = `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["count"],"values":[[42]]}]}]}`
+		server.Responses["SHOW SERIES EXACT CARDINALITY"] = `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["count"],"values":[[42]]},{"name":"mem","columns":["count"],"values":[[8]]}]}]}`
+		if n, err := driver.SeriesCardinalityExact("cpu"); err != nil {
+			t.Error(err)
+		} else if n != 42 {
+			t.Error("Expected 42, got", n)
+		}
+		if n, err := driver.SeriesCardinalityExact(""); err != nil {
+			t.Error(err)
+		} else if n != 50 {
+			t.Error("Expected 50, got", n)
+		}
+	}
+
+	// Earlier versions are not supported
+	server.Version = "1.3.9"
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+		if _, err := driver.SeriesCardinalityExact("cpu"); errors.Is(err, influxdb.ErrNotSupported) == false {
+			t.Error("Expected ErrNotSupported, got", err)
+		}
+	}
+}
+
+func TestPool_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	server.Responses["SHOW MEASUREMENTS ON test"] = `{"results":[{"statement_id":0}]}`
+
+	// Count the connections made to the server
+	var connections int32
+	server.Lock()
+	server.ConnState = func(conn net.Conn, s http.ConnState) {
+		if s == http.StateNew {
+			atomic.AddInt32(&connections, 1)
+		}
+	}
+	server.Unlock()
+
+	u, _ := url.Parse(server.URL)
+	port, _ := strconv.ParseUint(u.Port(), 10, 32)
+	log, err := gopi.Open(logger.Config{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := v2.NewPool(v2.Config{Host: u.Hostname(), Port: uint(port), Database: "test"}, log.(gopi.Logger))
+	defer pool.Close()
+
+	for i := 0; i < 3; i++ {
+		if client, err := pool.Get(); err != nil {
+			t.Fatal(err)
+		} else if _, err := client.GetMeasurements(""); err != nil {
+			t.Error(err)
+		} else if err := pool.Put(client); err != nil {
+			t.Error(err)
+		}
+	}
+
+	// Clients opened concurrently share the transport
+	first, err := pool.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := pool.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == second {
+		t.Error("Expected a new client when there are no idle clients")
+	}
+	second.GetMeasurements("")
+	pool.Put(second)
+	first.Close()
+	if err := pool.Put(first); errors.Is(err, influxdb.ErrNotConnected) == false {
+		t.Error("Expected ErrNotConnected, got", err)
+	}
+	if n := atomic.LoadInt32(&connections); n != 1 {
+		t.Error("Expected one connection, got", n)
+	}
+}
+
+func TestBufferedWriter_004(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+
+	// Writes block until released
+	started := make(chan struct{}, 10)
+	release := make(chan struct{})
+	server.Handlers["/write"] = func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		w.WriteHeader(http.StatusNoContent)
+	}
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		point := func(i int) *influxdb.Point {
+			return &influxdb.Point{Measurement: "cpu", Fields: map[string]interface{}{"value": float64(i)}, Time: time.Unix(int64(i), 0)}
+		}
+
+		// With the reject policy, a full queue returns ErrQueueFull
+		writer := influxdb.NewBufferedWriter(driver, 2, 0)
+		writer.MaxQueue = 4
+		writer.QueuePolicy = influxdb.QueueReject
+		flushed := make(chan error, 1)
+		go func() { flushed <- writer.Write(point(1), point(2)) }()
+		<-started
+		if err := writer.Write(point(3)); err != nil {
+			t.Error(err)
+		} else if err := writer.Write(point(4), point(5)); errors.Is(err, influxdb.ErrQueueFull) == false {
+			t.Error("Expected ErrQueueFull, got", err)
+		} else if err := writer.Write(point(1), point(2), point(3), point(4), point(5)); errors.Is(err, influxdb.ErrQueueFull) == false {
+			t.Error("Expected ErrQueueFull, got", err)
+		}
+		release <- struct{}{}
+		if err := <-flushed; err != nil {
+			t.Error(err)
+		}
+
+		// With the block policy, Write waits for the write in progress
+		writer.QueuePolicy = influxdb.QueueBlock
+		go func() { flushed <- writer.Write(point(4)) }()
+		<-started
+		blocked := make(chan error, 1)
+		go func() { blocked <- writer.Write(point(5), point(6), point(7)) }()
+		select {
+		case err := <-blocked:
+			t.Error("Expected write to block, got", err)
+		case <-time.After(50 * time.Millisecond):
+		}
+		close(release)
+		if err := <-flushed; err != nil {
+			t.Error(err)
+		} else if err := <-blocked; err != nil {
+			t.Error(err)
+		} else if err := writer.Close(); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestQueryCSV_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		var accept string
+		server.Handlers["/query"] = func(w http.ResponseWriter, r *http.Request) {
+			accept = r.Header.Get("Accept")
+			w.Header().Set("Content-Type", "application/csv")
+			w.Write([]byte("name,tags,time,host,value\n" +
+				"cpu,,1514764800000000000,a,1\n" +
+				"cpu,,1514764801000000000,\"b,c\",2\n"))
+		}
+		if records, err := driver.QueryCSV("SELECT * FROM cpu"); err != nil {
+			t.Error(err)
+		} else if accept != "application/csv" {
+			t.Error("Expected CSV to be requested, got", accept)
+		} else if len(records) != 3 {
+			t.Error("Expected three records, got", records)
+		} else if strings.Join(records[0], ",") != "name,tags,time,host,value" {
+			t.Error("Unexpected header:", records[0])
+		} else if records[2][3] != "b,c" || records[2][4] != "2" {
+			t.Error("Unexpected record:", records[2])
+		}
+
+		// A JSON response is not parsed as CSV
+		server.Handlers["/query"] = func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"results":[{"statement_id":0}]}`))
+		}
+		if _, err := driver.QueryCSV("SELECT * FROM cpu"); errors.Is(err, influxdb.ErrUnexpectedResponse) == false {
+			t.Error("Expected ErrUnexpectedResponse, got", err)
+		}
+	}
+}
+
+func TestSchemaCache_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriverConfig(t, server, v2.Config{Database: "test", SchemaCacheTTL: time.Hour}); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		server.Responses["SHOW FIELD KEYS FROM cpu"] = `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["fieldKey","fieldType"],"values":[["value","float"]]}]}]}`
+		count := func() int {
+			n := 0
+			for _, query := range server.Queries {
+				if query == "SHOW FIELD KEYS FROM cpu" {
+					n++
+				}
+			}
+			return n
+		}
+		point := &influxdb.Point{Measurement: "cpu", Fields: map[string]interface{}{"value": "x"}}
+
+		// The second validation within the ttl does not query the server
+		for i := 0; i < 2; i++ {
+			if err := driver.ValidatePoint(point); errors.Is(err, influxdb.ErrFieldTypeConflict) == false {
+				t.Error("Expected ErrFieldTypeConflict, got", err)
+			}
+		}
+		if n := count(); n != 1 {
+			t.Error("Expected one query for field keys, got", n)
+		}
+
+		// Refreshing the schema queries the server again
+		driver.RefreshSchema("cpu")
+		if err := driver.ValidatePoint(point); errors.Is(err, influxdb.ErrFieldTypeConflict) == false {
+			t.Error("Expected ErrFieldTypeConflict, got", err)
+		} else if n := count(); n != 2 {
+			t.Error("Expected two queries for field keys, got", n)
+		}
+	}
+}
+
+func TestSplitQualifiedName_001(t *testing.T) {
+	tests := map[string][3]string{
+		"cpu":                                 {"", "", "cpu"},
+		"autogen.cpu":                         {"", "autogen", "cpu"},
+		"\"my db\".\"auto gen\".\"cpu load\"": {"my db", "auto gen", "cpu load"},
+		"\"my.db\".\"rp.1\".\"cpu.load\"":     {"my.db", "rp.1", "cpu.load"},
+		"\"db\"..\"a \\\"b\\\" c\"":           {"db", "", "a \"b\" c"},
+	}
+	for value, expected := range tests {
+		if db, rp, measurement, err := influxdb.SplitQualifiedName(value); err != nil {
+			t.Error(value, err)
+		} else if [3]string{db, rp, measurement} != expected {
+			t.Errorf("For [%v], expected %q, got %q", value, expected, [3]string{db, rp, measurement})
+		}
+	}
+	for _, value := range []string{"", "\"my db\".\"auto gen", "a.b.c.d"} {
+		if _, _, _, err := influxdb.SplitQualifiedName(value); err != influxdb.ErrBadParameter {
+			t.Errorf("For [%v], expected ErrBadParameter, got %v", value, err)
+		}
+	}
+}
+
+func TestWriteJSONStream_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriverConfig(t, server, v2.Config{Database: "test", WriteBatchSize: 2}); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		stream := `{"measurement":"cpu","tags":{"host":"a"},"fields":{"value":1},"time":"2018-01-01T00:00:01Z"}
+			{"measurement":"cpu","tags":{"host":"b"},"fields":{"value":2},"time":"2018-01-01T00:00:02Z"}
+			{"measurement":"cpu","tags":{"host":"c"},"fields":{"value":3},"time":"2018-01-01T00:00:03Z"}`
+
+		// The final partial batch is written
+		if n, err := driver.WriteJSONStream(context.Background(), strings.NewReader(stream)); err != nil {
+			t.Error(err)
+		} else if n != 3 {
+			t.Error("Expected three points written, got", n)
+		} else if len(server.Writes) != 2 || len(server.Writes[0].Lines) != 2 || len(server.Writes[1].Lines) != 1 {
+			t.Error("Expected writes of two points and one point, got", server.Writes)
+		} else if server.Writes[1].Lines[0] != "cpu,host=c value=3 1514764803000000000" {
+			t.Error("Unexpected line", server.Writes[1].Lines[0])
+		}
+
+		// Cancelling after the first batch stops the stream
+		server.Writes = nil
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		reader, writer := io.Pipe()
+		go func() {
+			for i := 0; i < 2; i++ {
+				writer.Write([]byte(`{"measurement":"cpu","fields":{"value":1}}`))
+			}
+			for written := false; written == false; time.Sleep(time.Millisecond) {
+				server.Lock()
+				written = len(server.Writes) > 0
+				server.Unlock()
+			}
+			cancel()
+			writer.Write([]byte(`{"measurement":"cpu","fields":{"value":2}}`))
+			writer.Close()
+		}()
+		if n, err := driver.WriteJSONStream(ctx, reader); errors.Is(err, context.Canceled) == false {
+			t.Error("Expected context.Canceled, got", err)
+		} else if n != 2 {
+			t.Error("Expected two points written, got", n)
+		} else if len(server.Writes) != 1 {
+			t.Error("Expected one write, got", len(server.Writes))
+		}
+
+		// Invalid JSON
+		if _, err := driver.WriteJSONStream(context.Background(), strings.NewReader(`{"measurement":`)); errors.Is(err, influxdb.ErrBadParameter) == false {
+			t.Error("Expected ErrBadParameter, got", err)
+		}
+	}
+}
+
+func TestQueryGrouped_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		server.Responses["SELECT mean(value) FROM cpu GROUP BY host"] = `{"results":[{"statement_id":0,"series":[` +
+			`{"name":"cpu","tags":{"host":"a"},"columns":["time","mean"],"values":[["1970-01-01T00:00:00Z",1]]},` +
+			`{"name":"cpu","tags":{"host":"b"},"columns":["time","mean"],"values":[["1970-01-01T00:00:00Z",2],["1970-01-01T00:00:01Z",3]]}]}]}`
+		if grouped, err := driver.QueryGrouped("SELECT mean(value) FROM cpu GROUP BY host"); err != nil {
+			t.Error(err)
+		} else if len(grouped) != 2 {
+			t.Error("Expected two series, got", grouped)
+		} else if a, exists := grouped["cpu,host=a"]; exists == false || len(a.Values) != 1 || a.Tags["host"] != "a" {
+			t.Error("Unexpected series for host a:", a)
+		} else if b, exists := grouped["cpu,host=b"]; exists == false || len(b.Values) != 2 || b.Tags["host"] != "b" {
+			t.Error("Unexpected series for host b:", b)
+		}
+
+		// No data
+		server.Responses["SELECT mean(value) FROM mem GROUP BY host"] = `{"results":[{"statement_id":0}]}`
+		if grouped, err := driver.QueryGrouped("SELECT mean(value) FROM mem GROUP BY host"); err != nil {
+			t.Error(err)
+		} else if len(grouped) != 0 {
+			t.Error("Expected no series, got", grouped)
+		}
+	}
+}
+
+func TestRetryAfter_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+
+	// The first write is throttled
+	var attempts int32
+	server.Handlers["/write"] = func(w http.ResponseWriter, r *http.Request) {
+		if body, _ := ioutil.ReadAll(r.Body); string(body) != "cpu value=1 1000000000" {
+			t.Error("Unexpected body", string(body))
+		}
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+		} else {
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		start := time.Now()
+		if err := driver.WritePoint(&influxdb.Point{Measurement: "cpu", Fields: map[string]interface{}{"value": 1.0}, Time: time.Unix(1, 0)}); err != nil {
+			t.Error(err)
+		} else if n := atomic.LoadInt32(&attempts); n != 2 {
+			t.Error("Expected two attempts, got", n)
+		} else if elapsed := time.Since(start); elapsed < time.Second {
+			t.Error("Expected retry after one second, got", elapsed)
+		}
+
+		// Cancelling while waiting returns the context error
+		atomic.StoreInt32(&attempts, 0)
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		if err := driver.WriteBatchContext(ctx, []*influxdb.Point{
+			{Measurement: "cpu", Fields: map[string]interface{}{"value": 1.0}, Time: time.Unix(1, 0)},
+		}); errors.Is(err, context.DeadlineExceeded) == false {
+			t.Error("Expected context.DeadlineExceeded, got", err)
+		}
+	}
+}
+
+func TestCopyRetentionPolicy_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		server.Responses["SHOW RETENTION POLICIES ON test"] = `{"results":[{"statement_id":0,"series":[{"columns":["name","duration","shardGroupDuration","replicaN","default"],"values":[["autogen","0s","168h0m0s",1,false],["year","8760h0m0s","24h0m0s",2,true]]}]}]}`
+		server.Responses["SHOW RETENTION POLICIES ON other"] = `{"results":[{"statement_id":0,"series":[{"columns":["name","duration","shardGroupDuration","replicaN","default"],"values":[["autogen","0s","168h0m0s",1,true]]}]}]}`
+		server.Responses["CREATE RETENTION POLICY year ON other DURATION 8760h0m0s REPLICATION 2 SHARD DURATION 24h0m0s DEFAULT"] = `{"results":[{"statement_id":0}]}`
+		if err := driver.CopyRetentionPolicy("test", "year", "other"); err != nil {
+			t.Error(err)
+		} else if server.HasQuery("CREATE RETENTION POLICY year ON other DURATION 8760h0m0s REPLICATION 2 SHARD DURATION 24h0m0s DEFAULT") == false {
+			t.Error("Expected create statement, got", server.Queries)
+		}
+		if err := driver.CopyRetentionPolicy("test", "forever", "other"); errors.Is(err, influxdb.ErrNotFound) == false {
+			t.Error("Expected ErrNotFound, got", err)
+		}
+		if err := driver.CopyRetentionPolicy("test", "autogen", "other"); errors.Is(err, influxdb.ErrAlreadyExists) == false {
+			t.Error("Expected ErrAlreadyExists, got", err)
+		}
+	}
+}
+
+func TestQueries_040(t *testing.T) {
+	requests := &influxdb.Measurement{Name: "http"}
+	if query, err := influxdb.Select(requests).GroupByTime(time.Hour).Distinct("status"); err != nil {
+		t.Error(err)
+	} else if query.String() != "SELECT distinct(status) FROM http GROUP BY time(1h)" {
+		t.Errorf("Unexpected query: %v", query.String())
+	}
+	if query, err := influxdb.Select(requests).Distinct("status code"); err != nil {
+		t.Error(err)
+	} else if query.String() != "SELECT distinct(\"status code\") FROM http" {
+		t.Errorf("Unexpected query: %v", query.String())
+	}
+
+	// Distinct cannot be combined with other columns
+	if _, err := influxdb.Select(requests).Columns(influxdb.Count("status")).Distinct("status"); errors.Is(err, influxdb.ErrBadParameter) == false {
+		t.Error("Expected ErrBadParameter, got", err)
+	}
+	if query, err := influxdb.Select(requests).Distinct("status"); err != nil {
+		t.Error(err)
+	} else if _, err := query.Distinct("host"); errors.Is(err, influxdb.ErrBadParameter) == false {
+		t.Error("Expected ErrBadParameter, got", err)
+	}
+}
+
+func TestQueries_041(t *testing.T) {
+	query := influxdb.Select().FromPolicies("cpu", "raw", "one year").Columns(influxdb.Mean("value"))
+	if query.String() != "SELECT mean(value) FROM \"raw\".\"cpu\",\"one year\".\"cpu\"" {
+		t.Errorf("Unexpected query: %v", query.String())
+	}
+}
+
+func TestWriteBatch_004(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		// A point with a zero time is written without a timestamp
+		if err := driver.WriteBatch([]*influxdb.Point{
+			{Measurement: "cpu", Tags: map[string]string{"host": "a"}, Fields: map[string]interface{}{"value": 1.0}},
+			{Measurement: "cpu", Tags: map[string]string{"host": "b"}, Fields: map[string]interface{}{"value": 2.0}, Time: time.Unix(2, 0)},
+		}); err != nil {
+			t.Error(err)
+		} else if len(server.Writes) != 1 {
+			t.Error("Expected one write, got", len(server.Writes))
+		} else if lines := server.Writes[0].Lines; len(lines) != 2 {
+			t.Error("Expected two lines, got", lines)
+		} else if lines[0] != "cpu,host=a value=1" {
+			t.Errorf("Expected no timestamp, got %q", lines[0])
+		} else if lines[1] != "cpu,host=b value=2 2000000000" {
+			t.Errorf("Expected timestamp, got %q", lines[1])
+		}
+	}
+}
+
+func TestDefaultRetentionPolicy_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		server.Responses["SHOW RETENTION POLICIES ON test"] = `{"results":[{"statement_id":0,"series":[{"columns":["name","duration","shardGroupDuration","replicaN","default"],"values":[["autogen","0s","168h0m0s",1,false],["week","168h0m0s","24h0m0s",1,true],["year","8760h0m0s","168h0m0s",1,false]]}]}]}`
+		server.Responses["SHOW RETENTION POLICIES ON other"] = `{"results":[{"statement_id":0,"series":[{"columns":["name","duration","shardGroupDuration","replicaN","default"],"values":[["autogen","0s","168h0m0s",1,false]]}]}]}`
+		if name, err := driver.DefaultRetentionPolicy(""); err != nil {
+			t.Error(err)
+		} else if name != "week" {
+			t.Error("Expected week, got", name)
+		}
+		if name, err := driver.DefaultRetentionPolicy("test"); err != nil {
+			t.Error(err)
+		} else if name != "week" {
+			t.Error("Expected week, got", name)
+		}
+		if _, err := driver.DefaultRetentionPolicy("other"); errors.Is(err, influxdb.ErrNotFound) == false {
+			t.Error("Expected ErrNotFound, got", err)
+		}
+	}
+}
+
+func TestCreateSnapshot_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		// No request is made to the server
+		server.Queries = nil
+		if err := driver.CreateSnapshot(""); errors.Is(err, influxdb.ErrNotSupported) == false {
+			t.Error("Expected ErrNotSupported, got", err)
+		} else if strings.Contains(err.Error(), "1.5.2") == false {
+			t.Error("Expected error to name the server version, got", err)
+		} else if len(server.Queries) != 0 {
+			t.Error("Expected no queries, got", server.Queries)
+		}
+	}
+}
+
+func TestWaitReady_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+
+	// The server is available after the second poll
+	var polls int32
+	server.Handlers["/health"] = func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&polls, 1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"name":"influxdb","message":"starting","status":"fail","checks":[],"version":"1.8.0"}`))
+		} else {
+			w.Write([]byte(`{"name":"influxdb","message":"ready for queries and writes","status":"pass","checks":[],"version":"1.8.0"}`))
+		}
+	}
+	if driver := StubDriverConfig(t, server, v2.Config{SkipPing: true}); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if err := driver.WaitReady(ctx, 10*time.Millisecond); err != nil {
+			t.Error(err)
+		} else if n := atomic.LoadInt32(&polls); n != 3 {
+			t.Error("Expected three polls, got", n)
+		}
+
+		// The context expires before the server is ready
+		atomic.StoreInt32(&polls, -100)
+		ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		if err := driver.WaitReady(ctx, 10*time.Millisecond); errors.Is(err, context.DeadlineExceeded) == false {
+			t.Error("Expected context.DeadlineExceeded, got", err)
+		}
+	}
+}
+
+func TestExportSeriesByGroup_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		series := func(hosts ...string) string {
+			s := make([]string, len(hosts))
+			for i, host := range hosts {
+				s[i] = `{"name":"cpu","tags":{"host":"` + host + `"},"columns":["time","value"],"values":[["1970-01-01T00:00:00Z",1]]}`
+			}
+			return `{"results":[{"statement_id":0,"series":[` + strings.Join(s, ",") + `]}]}`
+		}
+		server.Responses["SELECT * FROM cpu GROUP BY * SLIMIT 2"] = series("a", "b")
+		server.Responses["SELECT * FROM cpu GROUP BY * SLIMIT 2 SOFFSET 2"] = series("c", "d")
+		server.Responses["SELECT * FROM cpu GROUP BY * SLIMIT 2 SOFFSET 4"] = series("e")
+		server.Queries = nil
+
+		hosts := []string{}
+		if err := driver.ExportSeriesByGroup("cpu", 2, func(r *influxdb.Result) error {
+			hosts = append(hosts, r.Tags["host"])
+			return nil
+		}); err != nil {
+			t.Error(err)
+		} else if strings.Join(hosts, ",") != "a,b,c,d,e" {
+			t.Error("Unexpected series", hosts)
+		} else if len(server.Queries) != 3 {
+			t.Error("Expected three pages, got", server.Queries)
+		}
+
+		// An error from the callback stops the export
+		server.Queries = nil
+		if err := driver.ExportSeriesByGroup("cpu", 2, func(r *influxdb.Result) error {
+			return io.EOF
+		}); err != io.EOF {
+			t.Error("Expected io.EOF, got", err)
+		} else if len(server.Queries) != 1 {
+			t.Error("Expected one page, got", server.Queries)
+		}
+	}
+}
+
+func TestCheckQuery_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		server.Responses["SHOW TAG KEYS FROM cpu"] = `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["tagKey"],"values":[["host"],["rack"]]}]}]}`
+		cpu := &influxdb.Measurement{Name: "cpu"}
+
+		// Comparing a tag with a number is reported
+		query := influxdb.Select(cpu).Where(influxdb.And(influxdb.Equals("rack", 5), influxdb.GreaterThan("value", 0.5)))
+		if warnings, err := driver.CheckQuery(query); err != nil {
+			t.Error(err)
+		} else if len(warnings) != 1 {
+			t.Error("Expected one warning, got", warnings)
+		} else if warnings[0] != "rack = 5: tag rack is a string, compare with '5'" {
+			t.Error("Unexpected warning", warnings[0])
+		}
+
+		// Comparing a tag with a string is not reported
+		query = influxdb.Select(cpu).Where(influxdb.Not(influxdb.Equals("rack", "5")))
+		if warnings, err := driver.CheckQuery(query); err != nil {
+			t.Error(err)
+		} else if len(warnings) != 0 {
+			t.Error("Expected no warnings, got", warnings)
+		}
+	}
+}
+
+func TestResultTime_001(t *testing.T) {
+	result := &influxdb.Result{
+		Columns: []string{"time", "value"},
+		Values: [][]interface{}{
+			{json.Number("1514764800123"), json.Number("1")},
+			{"2018-01-01T00:00:00.123Z", json.Number("2")},
+		},
+	}
+	expected := time.Date(2018, 1, 1, 0, 0, 0, 123000000, time.UTC)
+
+	// Without a precision, times are RFC3339
+	if ts, err := result.Time(1, 0); err != nil {
+		t.Error(err)
+	} else if ts.Equal(expected) == false {
+		t.Error("Expected", expected, "got", ts)
+	} else if _, err := result.Time(0, 0); err != influxdb.ErrUnexpectedResponse {
+		t.Error("Expected ErrUnexpectedResponse, got", err)
+	}
+
+	// Integer times in milliseconds
+	result.SetTimePrecision(influxdb.PRECISION_MILLI)
+	if ts, err := result.Time(0, 0); err != nil {
+		t.Error(err)
+	} else if ts.Equal(expected) == false {
+		t.Error("Expected", expected, "got", ts)
+	} else if _, err := result.Time(2, 0); err != influxdb.ErrBadParameter {
+		t.Error("Expected ErrBadParameter, got", err)
+	}
+	result.SetTimePrecision("x")
+	if _, err := result.Time(0, 0); errors.Is(err, influxdb.ErrBadParameter) == false {
+		t.Error("Expected ErrBadParameter, got", err)
+	}
+}
+
+func TestBootstrapAdmin_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, ""); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		// A fresh install rejects SHOW USERS until an admin user is created
+		create := "CREATE USER \"admin\" WITH PASSWORD 'secret' WITH ALL PRIVILEGES"
+		server.Responses["SHOW USERS"] = `{"results":[{"statement_id":0,"error":"error authorizing query: create admin user first or disable authentication"}]}`
+		server.Responses[create] = `{"results":[{"statement_id":0}]}`
+		if err := driver.BootstrapAdmin("admin", "secret"); err != nil {
+			t.Error(err)
+		} else if server.HasQuery(create) == false {
+			t.Error("Expected", create, "got", server.Queries)
+		}
+
+		// An existing admin user is not replaced
+		server.Queries = nil
+		server.Responses["SHOW USERS"] = `{"results":[{"statement_id":0,"series":[{"columns":["user","admin"],"values":[["root",true]]}]}]}`
+		if err := driver.BootstrapAdmin("admin", "secret"); errors.Is(err, influxdb.ErrAlreadyExists) == false {
+			t.Error("Expected ErrAlreadyExists, got", err)
+		} else if server.HasQuery(create) {
+			t.Error("Unexpected", create)
+		}
+		if err := driver.BootstrapAdmin("admin", ""); err != influxdb.ErrBadParameter {
+			t.Error("Expected ErrBadParameter, got", err)
+		}
+	}
+}
+
+func TestIdleConnTimeout_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	server.Responses["SHOW MEASUREMENTS ON test"] = `{"results":[{"statement_id":0}]}`
+
+	// Count the connections closed by the client
+	var closed int32
+	server.Lock()
+	server.ConnState = func(conn net.Conn, s http.ConnState) {
+		if s == http.StateClosed {
+			atomic.AddInt32(&closed, 1)
+		}
+	}
+	server.Unlock()
+
+	if driver := StubDriverConfig(t, server, v2.Config{Database: "test", KeepAlive: time.Second, IdleConnTimeout: 50 * time.Millisecond}); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+		if _, err := driver.GetMeasurements(""); err != nil {
+			t.Error(err)
+		}
+		time.Sleep(250 * time.Millisecond)
+		if atomic.LoadInt32(&closed) == 0 {
+			t.Error("Expected idle connections to be closed after the idle connection timeout")
+		}
+	}
+	if err := (v2.Config{Host: "localhost", IdleConnTimeout: -time.Second}).Validate(); errors.Is(err, influxdb.ErrBadParameter) == false {
+		t.Error("Expected ErrBadParameter, got", err)
+	}
+}
+
+func TestFlattenTags_001(t *testing.T) {
+	result := &influxdb.Result{
+		Name:    "cpu",
+		Tags:    map[string]string{"host": "a", "dc": "east"},
+		Columns: []string{"time", "mean"},
+		Values: [][]interface{}{
+			{"2018-01-01T00:00:00Z", json.Number("1")},
+			{"2018-01-01T01:00:00Z", json.Number("2")},
+		},
+	}
+	flat := result.FlattenTags()
+	if strings.Join(flat.Columns, ",") != "dc,host,time,mean" {
+		t.Error("Unexpected columns", flat.Columns)
+	} else if len(flat.Tags) != 0 {
+		t.Error("Unexpected tags", flat.Tags)
+	} else if row := flat.RowMap(1); row["host"] != "a" || row["dc"] != "east" || row["mean"] != json.Number("2") {
+		t.Error("Unexpected row", row)
+	} else if len(result.Columns) != 2 || len(result.Values[0]) != 2 {
+		t.Error("Expected the result to be unchanged")
+	}
+}
+
+func TestBufferedWriter_005(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		// Every write fails with a different error
+		var writes int32
+		written := make(chan struct{}, 10)
+		server.Handlers["/write"] = func(w http.ResponseWriter, r *http.Request) {
+			io.Copy(ioutil.Discard, r.Body)
+			n := atomic.AddInt32(&writes, 1)
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error":"write ` + strconv.Itoa(int(n)) + ` failed"}`))
+			written <- struct{}{}
+		}
+
+		// The first point is flushed in the background and the second on close
+		writer := influxdb.NewBufferedWriter(driver, 10, 10*time.Millisecond)
+		if err := writer.Write(&influxdb.Point{Measurement: "cpu", Fields: map[string]interface{}{"value": 1.0}}); err != nil {
+			t.Fatal(err)
+		}
+		<-written
+		if err := writer.Write(&influxdb.Point{Measurement: "cpu", Fields: map[string]interface{}{"value": 2.0}}); err != nil {
+			t.Fatal(err)
+		}
+		if err := writer.Close(); err == nil {
+			t.Error("Expected errors from close")
+		} else if strings.Contains(err.Error(), "write 1 failed") == false || strings.Contains(err.Error(), "write 2 failed") == false {
+			t.Error("Expected both write errors, got", err)
+		}
+	}
+}
+
+func TestShowTagValues_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		server.Responses[`SHOW TAG VALUES FROM cpu WITH KEY IN ("host","region")`] = `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["key","value"],"values":[["host","a"],["host","b"],["region","east"]]}]}]}`
+		if values, err := driver.ShowTagValues("cpu", "host", "region"); err != nil {
+			t.Error(err)
+		} else if len(values) != 2 || strings.Join(values["host"], ",") != "a,b" || strings.Join(values["region"], ",") != "east" {
+			t.Error("Unexpected tag values", values)
+		}
+		if values, err := driver.ShowTagValues("cpu", "host"); err != nil {
+			t.Error(err)
+		} else if len(values) != 0 {
+			t.Error("Expected no tag values, got", values)
+		} else if server.HasQuery(`SHOW TAG VALUES FROM cpu WITH KEY = "host"`) == false {
+			t.Error("Unexpected queries", server.Queries)
+		}
+	}
+	if query := influxdb.ShowTagValues().Database("test").Measurement(&influxdb.Measurement{Name: "cpu load"}); query.String() != `SHOW TAG VALUES ON test FROM "cpu load" WITH KEY =~ /.*/` {
+		t.Errorf("Unexpected query: %v", query.String())
+	}
+}
+
+func TestResultToLineProtocol_001(t *testing.T) {
+	result := &influxdb.Result{
+		Name:    "cpu",
+		Tags:    map[string]string{"host": "a"},
+		Columns: []string{"time", "count", "mean", "status"},
+		Values: [][]interface{}{
+			{"2018-01-01T00:00:00Z", json.Number("1"), json.Number("0.5"), "ok"},
+			{"2018-01-01T00:00:01Z", json.Number("2"), json.Number("1"), nil},
+			{"2018-01-01T00:00:02Z", nil, nil, nil},
+		},
+	}
+	expected := []string{
+		`cpu,host=a count=1,mean=0.5,status="ok" 1514764800`,
+		`cpu,host=a count=2,mean=1 1514764801`,
+	}
+	if lines, err := result.ToLineProtocol(influxdb.PRECISION_SECOND); err != nil {
+		t.Error(err)
+	} else if strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+		t.Error("Unexpected lines", lines)
+	} else {
+		// The lines can be written back to the server
+		for _, line := range lines {
+			if err := influxdb.ValidateLineProtocol(line); err != nil {
+				t.Error(err)
+			}
+		}
+	}
+
+	// Integer fields keep their type with the field types of the measurement
+	server := NewStubServer()
+	defer server.Close()
+	driver := StubDriver(t, server, "test")
+	if driver == nil {
+		t.Fatal("nil driver returned")
+	}
+	defer driver.Close()
+	server.Responses["SHOW FIELD KEYS FROM cpu"] = `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["fieldKey","fieldType"],"values":[["count","integer"],["mean","float"],["status","string"]]}]}]}`
+	if fields, err := driver.ShowFieldKeys("cpu"); err != nil {
+		t.Fatal(err)
+	} else {
+		result.SetFieldTypes(fields)
+	}
+	if lines, err := result.ToLineProtocol(influxdb.PRECISION_SECOND); err != nil {
+		t.Error(err)
+	} else if strings.Join(lines, "\n") != strings.Join([]string{
+		`cpu,host=a count=1i,mean=0.5,status="ok" 1514764800`,
+		`cpu,host=a count=2i,mean=1 1514764801`,
+	}, "\n") {
+		t.Error("Unexpected lines", lines)
+	}
+	if lines, err := result.ToLineProtocol(""); err != nil {
+		t.Error(err)
+	} else if len(lines) != 2 || strings.HasSuffix(lines[0], " 1514764800000000000") == false {
+		t.Error("Expected nanosecond timestamps, got", lines)
+	}
+	if _, err := result.ToLineProtocol("y"); errors.Is(err, influxdb.ErrBadParameter) == false {
+		t.Error("Expected ErrBadParameter, got", err)
+	}
+}
+
+func TestPublishExpvar_001(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		// Variables cannot be removed, so the prefix is unique to each run
+		prefix := "influxdb_test_" + strconv.FormatInt(time.Now().UnixNano(), 10)
+		if err := driver.PublishExpvar(prefix); err != nil {
+			t.Fatal(err)
+		} else if err := driver.PublishExpvar(prefix); errors.Is(err, influxdb.ErrAlreadyExists) == false {
+			t.Error("Expected ErrAlreadyExists, got", err)
+		}
+
+		// Counters reflect the queries and writes made after publishing
+		queries := expvar.Get(prefix + ".queries").String()
+		server.Handlers["/write"] = func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error":"write failed"}`))
+		}
+		if _, err := driver.GetMeasurements(""); err != nil {
+			t.Error(err)
+		} else if err := driver.WritePoint(&influxdb.Point{Measurement: "cpu", Fields: map[string]interface{}{"value": 1.0}}); err == nil {
+			t.Error("Expected write error")
+		}
+		if value := expvar.Get(prefix + ".queries").String(); value == queries {
+			t.Error("Expected queries to be counted, got", value)
+		} else if value := expvar.Get(prefix + ".writes").String(); value != "1" {
+			t.Error("Expected one write, got", value)
+		} else if value := expvar.Get(prefix + ".errors").String(); value != "1" {
+			t.Error("Expected one error, got", value)
+		} else if value := expvar.Get(prefix + ".last_error").String(); strings.Contains(value, "write failed") == false {
+			t.Error("Expected last error, got", value)
+		}
+	}
+}
+
+func TestPublishExpvar_002(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		// Concurrent calls with the same prefix publish it once, without panic
+		prefix := "influxdb_test_" + strconv.FormatInt(time.Now().UnixNano(), 10)
+		errs := make(chan error, 10)
+		var wg sync.WaitGroup
+		for i := 0; i < cap(errs); i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs <- driver.PublishExpvar(prefix)
+			}()
+		}
+		wg.Wait()
+		close(errs)
+		published := 0
+		for err := range errs {
+			if err == nil {
+				published++
+			} else if errors.Is(err, influxdb.ErrAlreadyExists) == false {
+				t.Error("Expected ErrAlreadyExists, got", err)
+			}
+		}
+		if published != 1 {
+			t.Error("Expected one call to publish, got", published)
+		}
+	}
+}
+
+func TestBufferedWriter_006(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		// Points with only TimeUnixNano are deduplicated by that timestamp
+		writer := influxdb.NewBufferedWriter(driver, 10, 0)
+		writer.Dedup = true
+		if err := writer.Write(
+			&influxdb.Point{Measurement: "cpu", Fields: map[string]interface{}{"value": 1.0}, TimeUnixNano: 1000000001},
+			&influxdb.Point{Measurement: "cpu", Fields: map[string]interface{}{"value": 2.0}, TimeUnixNano: 1000000002},
+			&influxdb.Point{Measurement: "cpu", Fields: map[string]interface{}{"value": 3.0}, TimeUnixNano: 1000000002},
+		); err != nil {
+			t.Error(err)
+		} else if err := writer.Close(); err != nil {
+			t.Error(err)
+		}
+		server.Lock()
+		defer server.Unlock()
+		if len(server.Writes) != 1 {
+			t.Error("Expected one write, got", len(server.Writes))
+		} else if lines := strings.Join(server.Writes[0].Lines, "\n"); lines != "cpu value=1 1000000001\ncpu value=3 1000000002" {
+			t.Error("Unexpected lines", lines)
+		}
+	}
+}
+
+func TestRetryAfter_002(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	if driver := StubDriver(t, server, "test"); driver == nil {
+		t.Error("nil driver returned")
+	} else {
+		defer driver.Close()
+
+		// The first query is throttled and retried
+		var attempts int32
+		server.Handlers["SHOW MEASUREMENTS ON test"] = func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&attempts, 1) == 1 {
+				w.Header().Set("Retry-After", "1")
+				w.WriteHeader(http.StatusTooManyRequests)
+			} else {
+				w.Write([]byte(`{"results":[{"statement_id":0,"series":[{"name":"measurements","columns":["name"],"values":[["cpu"]]}]}]}`))
+			}
+		}
+		start := time.Now()
+		if measurements, err := driver.GetMeasurements("test"); err != nil {
+			t.Error(err)
+		} else if len(measurements) != 1 || measurements[0] != "cpu" {
+			t.Error("Unexpected measurements", measurements)
+		} else if n := atomic.LoadInt32(&attempts); n != 2 {
+			t.Error("Expected two attempts, got", n)
+		} else if elapsed := time.Since(start); elapsed < time.Second {
+			t.Error("Expected retry after one second, got", elapsed)
+		}
+
+		// A long delay is not waited for
+		server.Handlers["/write"] = func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Retry-After", "86400")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+		start = time.Now()
+		if err := driver.WritePoint(&influxdb.Point{Measurement: "cpu", Fields: map[string]interface{}{"value": 1.0}}); err == nil {
+			t.Error("Expected an error for a throttled write")
+		} else if elapsed := time.Since(start); elapsed > time.Second {
+			t.Error("Expected no retry, got", elapsed)
+		}
+	}
+}
+
+func TestPool_002(t *testing.T) {
+	server := NewStubServer()
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	port, _ := strconv.ParseUint(u.Port(), 10, 32)
+	log, err := gopi.Open(logger.Config{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := v2.NewPool(v2.Config{Host: u.Hostname(), Port: uint(port), Database: "test"}, log.(gopi.Logger))
+	defer pool.Close()
+
+	// Clients are reset when they are returned to the pool
+	client, err := pool.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	precision := client.Precision()
+	client.SetDryRun(true)
+	if err := client.SetPrecision(influxdb.PRECISION_SECOND); err != nil {
+		t.Fatal(err)
+	} else if err := pool.Put(client); err != nil {
+		t.Fatal(err)
+	} else if err := pool.Put(client); errors.Is(err, influxdb.ErrBadParameter) == false {
+		t.Error("Expected ErrBadParameter for a client returned twice, got", err)
+	}
+	if client, err := pool.Get(); err != nil {
+		t.Fatal(err)
+	} else if client.DryRun() {
+		t.Error("Expected dry run to be reset")
+	} else if client.Precision() != precision {
+		t.Error("Expected precision to be reset, got", client.Precision())
+	} else if err := pool.Put(client.WithDryRun(true).(*v2.Client)); errors.Is(err, influxdb.ErrBadParameter) == false {
+		t.Error("Expected ErrBadParameter for a copy, got", err)
+	} else if err := pool.Put(client.WithCache(time.Minute).(*v2.Client)); errors.Is(err, influxdb.ErrBadParameter) == false {
+		t.Error("Expected ErrBadParameter for a copy, got", err)
+	} else if err := pool.Put(client); err != nil {
+		t.Error(err)
+	}
+}
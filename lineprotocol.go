@@ -0,0 +1,320 @@
+/*
+	InfluxDB client
+	(c) Copyright David Thorpe 2017
+	All Rights Reserved
+
+	For Licensing and Usage information, please see LICENSE file
+*/
+
+package influxdb
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// GLOBALS
+
+var (
+	// regexpFloat matches a float field value in line protocol
+	regexpFloat = regexp.MustCompile("^[-+]?([0-9]+\\.?[0-9]*|\\.[0-9]+)([eE][-+]?[0-9]+)?$")
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// LineEncoder encodes points in the InfluxDB line protocol
+type LineEncoder struct {
+	// Unsigned allows unsigned integer fields, which are supported by
+	// InfluxDB 1.8 and later. When false, unsigned fields are rejected
+	Unsigned bool
+
+	// PreserveFieldOrder writes fields in the order of the point FieldOrder,
+	// followed by any other fields in key order. When false, all fields are
+	// written in key order
+	PreserveFieldOrder bool
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Encode returns a point as a line of line protocol with tags and fields
+// in key order and a nanosecond timestamp, or no timestamp when the point
+// time is zero. When TimeUnixNano is not zero it is written instead of
+// Time. The measurement should be a name without database or retention
+// policy. Integer fields are written with an "i" suffix and unsigned
+// integer fields with a "u" suffix. Returns ErrBadParameter for fields of
+// unsupported types, such as slices, and ErrNotSupported for unsigned
+// fields when Unsigned is false
+func (e LineEncoder) Encode(point *Point) (string, error) {
+	if point == nil || point.Measurement == "" {
+		return "", ErrBadParameter
+	}
+	if len(point.Fields) == 0 {
+		return "", fmt.Errorf("%w: point has no fields", ErrBadParameter)
+	}
+
+	// Measurement and tags
+	line := escapeMeasurement(point.Measurement)
+	for _, key := range sortedKeys(point.Tags) {
+		if value := point.Tags[key]; value != "" {
+			line += "," + escapeTag(key) + "=" + escapeTag(value)
+		}
+	}
+
+	// Fields
+	fields := make([]string, 0, len(point.Fields))
+	for _, key := range e.fieldKeys(point) {
+		if value, err := e.encodeField(key, point.Fields[key]); err != nil {
+			return "", err
+		} else {
+			fields = append(fields, escapeTag(key)+"="+value)
+		}
+	}
+	line += " " + strings.Join(fields, ",")
+
+	// Timestamp, which is omitted for a zero time so that the server
+	// assigns one
+	if point.TimeUnixNano != 0 {
+		line += " " + strconv.FormatInt(point.TimeUnixNano, 10)
+	} else if point.Time.IsZero() == false {
+		line += " " + strconv.FormatInt(point.Time.UnixNano(), 10)
+	}
+
+	// Return success
+	return line, nil
+}
+
+// fieldKeys returns the field keys of a point in the order they are written
+func (e LineEncoder) fieldKeys(point *Point) []string {
+	keys := make([]string, 0, len(point.Fields))
+	if e.PreserveFieldOrder {
+		seen := make(map[string]bool, len(point.FieldOrder))
+		for _, key := range point.FieldOrder {
+			if _, exists := point.Fields[key]; exists && seen[key] == false {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+		if len(keys) == len(point.Fields) {
+			return keys
+		}
+		rest := make([]string, 0, len(point.Fields)-len(keys))
+		for key := range point.Fields {
+			if seen[key] == false {
+				rest = append(rest, key)
+			}
+		}
+		sort.Strings(rest)
+		return append(keys, rest...)
+	}
+	for key := range point.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// FieldType returns the InfluxDB type of a field value, which is one of
+// "float", "integer", "unsigned", "boolean" or "string", or an empty
+// string if the value cannot be written
+func FieldType(value interface{}) string {
+	switch value.(type) {
+	case float64, float32:
+		return "float"
+	case int, int8, int16, int32, int64:
+		return "integer"
+	case uint, uint8, uint16, uint32, uint64:
+		return "unsigned"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	default:
+		return ""
+	}
+}
+
+// ValidateLineProtocol checks that a line of line protocol is well formed,
+// without contacting a server: the measurement, tag set, field set and
+// optional timestamp are checked for escaping, at least one field is
+// required and field values must be valid literals. Empty lines and
+// comments are valid. Returns an error wrapping ErrBadParameter
+func ValidateLineProtocol(line string) error {
+	if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+		return nil
+	}
+	if strings.HasSuffix(line, "\\") {
+		return fmt.Errorf("%w: line ends with an escape", ErrBadParameter)
+	}
+
+	// Measurement and tags, which end at the first unescaped space
+	end := indexUnescaped(line, ' ', false)
+	if end < 0 {
+		return fmt.Errorf("%w: missing field set", ErrBadParameter)
+	}
+	keys := splitUnescaped(line[:end], ',', false)
+	if keys[0] == "" {
+		return fmt.Errorf("%w: missing measurement", ErrBadParameter)
+	}
+	for _, tag := range keys[1:] {
+		if pair := splitUnescaped(tag, '=', false); len(pair) != 2 || pair[0] == "" || pair[1] == "" {
+			return fmt.Errorf("%w: invalid tag %q", ErrBadParameter, tag)
+		}
+	}
+
+	// Fields, which end at the next unescaped space outside quotes
+	rest := line[end+1:]
+	end = indexUnescaped(rest, ' ', true)
+	fields := rest
+	if end >= 0 {
+		fields = rest[:end]
+	}
+	if fields == "" {
+		return fmt.Errorf("%w: missing field set", ErrBadParameter)
+	}
+	for _, field := range splitUnescaped(fields, ',', true) {
+		pair := splitUnescaped(field, '=', true)
+		if len(pair) < 2 || pair[0] == "" {
+			return fmt.Errorf("%w: invalid field %q", ErrBadParameter, field)
+		} else if value := field[len(pair[0])+1:]; validFieldValue(value) == false {
+			return fmt.Errorf("%w: invalid value for field %q", ErrBadParameter, pair[0])
+		}
+	}
+
+	// Optional timestamp
+	if end >= 0 {
+		if _, err := strconv.ParseInt(rest[end+1:], 10, 64); err != nil {
+			return fmt.Errorf("%w: invalid timestamp %q", ErrBadParameter, rest[end+1:])
+		}
+	}
+
+	// Return success
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// encodeField returns the line protocol literal for a field value
+func (e LineEncoder) encodeField(key string, value interface{}) (string, error) {
+	switch value := value.(type) {
+	case float64:
+		return encodeFloat(key, value, 64)
+	case float32:
+		return encodeFloat(key, float64(value), 32)
+	case int:
+		return strconv.FormatInt(int64(value), 10) + "i", nil
+	case int8:
+		return strconv.FormatInt(int64(value), 10) + "i", nil
+	case int16:
+		return strconv.FormatInt(int64(value), 10) + "i", nil
+	case int32:
+		return strconv.FormatInt(int64(value), 10) + "i", nil
+	case int64:
+		return strconv.FormatInt(value, 10) + "i", nil
+	case uint:
+		return e.encodeUnsigned(key, uint64(value))
+	case uint8:
+		return e.encodeUnsigned(key, uint64(value))
+	case uint16:
+		return e.encodeUnsigned(key, uint64(value))
+	case uint32:
+		return e.encodeUnsigned(key, uint64(value))
+	case uint64:
+		return e.encodeUnsigned(key, value)
+	case bool:
+		return strconv.FormatBool(value), nil
+	case string:
+		return QuoteString(value), nil
+	default:
+		return "", fmt.Errorf("%w: field %q has unsupported type %T", ErrBadParameter, key, value)
+	}
+}
+
+// encodeUnsigned returns the literal for an unsigned field, or an error
+// if unsigned fields are not supported by the server
+func (e LineEncoder) encodeUnsigned(key string, value uint64) (string, error) {
+	if e.Unsigned == false {
+		return "", fmt.Errorf("%w: field %q is unsigned, which requires InfluxDB 1.8", ErrNotSupported, key)
+	}
+	return strconv.FormatUint(value, 10) + "u", nil
+}
+
+// encodeFloat returns the literal for a float field of bitSize 32 or
+// 64, which cannot be NaN or infinite
+func encodeFloat(key string, value float64, bitSize int) (string, error) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return "", fmt.Errorf("%w: field %q is not a finite number", ErrBadParameter, key)
+	}
+	return strconv.FormatFloat(value, 'f', -1, bitSize), nil
+}
+
+// indexUnescaped returns the index of the first occurrence of c which is
+// not escaped with a backslash, and when quotes is true is not within a
+// double-quoted string, or -1 if there is no such occurrence
+func indexUnescaped(value string, c byte, quotes bool) int {
+	quoted := false
+	for i := 0; i < len(value); i++ {
+		switch {
+		case value[i] == '\\':
+			i++
+		case quotes && value[i] == '"':
+			quoted = !quoted
+		case value[i] == c && quoted == false:
+			return i
+		}
+	}
+	return -1
+}
+
+// splitUnescaped splits a value at each occurrence of c which is not
+// escaped, and when quotes is true is not within a double-quoted string
+func splitUnescaped(value string, c byte, quotes bool) []string {
+	parts := make([]string, 0, 1)
+	for {
+		if i := indexUnescaped(value, c, quotes); i < 0 {
+			return append(parts, value)
+		} else {
+			parts = append(parts, value[:i])
+			value = value[i+1:]
+		}
+	}
+}
+
+// validFieldValue returns true if a field value is a valid string,
+// boolean, integer, unsigned integer or float literal
+func validFieldValue(value string) bool {
+	switch {
+	case value == "":
+		return false
+	case value[0] == '"':
+		return len(value) >= 2 && indexUnescaped(value[1:], '"', false) == len(value)-2
+	case value[len(value)-1] == 'i':
+		_, err := strconv.ParseInt(value[:len(value)-1], 10, 64)
+		return err == nil
+	case value[len(value)-1] == 'u':
+		_, err := strconv.ParseUint(value[:len(value)-1], 10, 64)
+		return err == nil
+	}
+	switch value {
+	case "t", "T", "true", "True", "TRUE", "f", "F", "false", "False", "FALSE":
+		return true
+	}
+	return regexpFloat.MatchString(value)
+}
+
+// sortedKeys returns the keys of a tag set, sorted lexicographically
+func sortedKeys(tags map[string]string) []string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,103 @@
+/*
+	InfluxDB client
+	(c) Copyright David Thorpe 2017
+	All Rights Reserved
+
+	For Licensing and Usage information, please see LICENSE file
+*/
+
+package influxdb
+
+import (
+	"strings"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// ParseMeasurement returns a measurement from a name which may be qualified
+// with a retention policy ("rp"."measurement") or with a database and
+// retention policy ("db"."rp"."measurement"). The database may be empty
+// ("db".."measurement") in which case the default database is used. Dots
+// separate the parts unless they are within double quotes, so a name
+// containing a dot or space must be quoted ("cpu.load"), and a quote
+// within a quoted part is escaped with a backslash. Returns
+// ErrBadParameter if the name is empty, has too many qualifiers or
+// has unbalanced quotes
+func ParseMeasurement(value string) (*Measurement, error) {
+	parts, err := splitIdentifiers(value)
+	if err != nil {
+		return nil, err
+	}
+	switch len(parts) {
+	case 1:
+		if parts[0] != "" {
+			return &Measurement{Name: parts[0]}, nil
+		}
+	case 2:
+		if parts[1] != "" {
+			return &Measurement{Policy: parts[0], Name: parts[1]}, nil
+		}
+	case 3:
+		if parts[2] != "" {
+			return &Measurement{Database: parts[0], Policy: parts[1], Name: parts[2]}, nil
+		}
+	}
+	return nil, ErrBadParameter
+}
+
+// SplitQualifiedName returns the database, retention policy and
+// measurement of a name, which are parsed as for ParseMeasurement so
+// that quoted parts may contain dots, spaces and escaped quotes. The
+// database and retention policy are empty when the name is not qualified
+func SplitQualifiedName(value string) (db, rp, measurement string, err error) {
+	if m, err := ParseMeasurement(value); err != nil {
+		return "", "", "", err
+	} else {
+		return m.Database, m.Policy, m.Name, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// splitIdentifiers splits a dot-separated list of identifiers, where each
+// identifier is either bare or enclosed in double quotes with backslash
+// escapes, and returns the unquoted identifiers
+func splitIdentifiers(value string) ([]string, error) {
+	parts := make([]string, 0, 3)
+	part := new(strings.Builder)
+	quoted, closed := false, false
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case quoted && c == '\\':
+			if i+1 >= len(value) {
+				return nil, ErrBadParameter
+			}
+			i++
+			part.WriteByte(value[i])
+		case quoted && c == '"':
+			quoted, closed = false, true
+		case quoted:
+			part.WriteByte(c)
+		case c == '.':
+			parts = append(parts, part.String())
+			part.Reset()
+			closed = false
+		case closed:
+			// Characters after a closing quote
+			return nil, ErrBadParameter
+		case c == '"' && part.Len() == 0:
+			quoted = true
+		case c == '"':
+			return nil, ErrBadParameter
+		default:
+			part.WriteByte(c)
+		}
+	}
+	if quoted {
+		return nil, ErrBadParameter
+	}
+	return append(parts, part.String()), nil
+}
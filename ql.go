@@ -10,7 +10,10 @@ package influxdb
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 )
 
 ///////////////////////////////////////////////////////////////////////////////
@@ -58,6 +61,12 @@ type q_ShowSeries struct {
 	offset      uint
 }
 
+type q_ShowSeriesCardinality struct {
+	database    string
+	measurement *Measurement
+	exact       bool
+}
+
 type q_ShowMeasurements struct {
 	database    string
 	measurement *Measurement
@@ -67,9 +76,90 @@ type q_ShowMeasurements struct {
 
 type q_Select struct {
 	measurement []*Measurement
+	from        []string
+	columns     []Predicate
+	into        *Measurement
 	where       []Predicate
+	group       []string
 	limit       uint
 	offset      uint
+	slimit      uint
+	soffset     uint
+	tz          string
+
+	// interval and intervalOffset group by time, when the interval is
+	// greater than zero
+	interval       time.Duration
+	intervalOffset time.Duration
+}
+
+type q_ShowQueries struct{}
+
+type q_KillQuery struct {
+	qid uint64
+}
+
+type q_ShowShards struct{}
+
+type q_ShowShardGroups struct{}
+
+type q_DropShard struct {
+	id uint64
+}
+
+type q_ShowDiagnostics struct{}
+
+type q_Raw struct {
+	statement string
+}
+
+type q_CreateContinuousQuery struct {
+	database string
+	name     string
+	query    Query
+}
+
+type q_ShowStats struct {
+	module string
+}
+
+type q_ShowFieldKeys struct {
+	database    string
+	measurement *Measurement
+}
+
+type q_ShowTagKeys struct {
+	database    string
+	measurement *Measurement
+}
+
+type q_ShowTagValues struct {
+	database    string
+	measurement *Measurement
+	keys        []string
+}
+
+type q_ShowUsers struct{}
+
+type q_ShowSubscriptions struct{}
+
+type q_DropMeasurement struct {
+	name string
+}
+
+type q_DropSeries struct {
+	measurement *Measurement
+	where       []Predicate
+}
+
+type q_ShowGrants struct {
+	user string
+}
+
+type q_CreateUser struct {
+	user     string
+	password string
+	admin    bool
 }
 
 type p_TagClause struct {
@@ -78,6 +168,48 @@ type p_TagClause struct {
 	op    string
 }
 
+type p_TagRegex struct {
+	name    string
+	pattern string
+	op      string
+}
+
+type p_TimeClause struct {
+	op     string
+	offset time.Duration
+}
+
+type p_TimeRange struct {
+	start time.Duration
+	end   time.Duration
+}
+
+type p_Compare struct {
+	name  string
+	op    string
+	value interface{}
+}
+
+type p_Logical struct {
+	op     string
+	values []Predicate
+}
+
+type p_Not struct {
+	value Predicate
+}
+
+// negater is implemented by predicates which can be inverted
+type negater interface {
+	negate() Predicate
+}
+
+type p_Function struct {
+	name  string
+	field string
+	args  []string
+}
+
 ///////////////////////////////////////////////////////////////////////////////
 // CONSTRUCT QUERIES
 
@@ -93,6 +225,12 @@ func ShowSeries() Query {
 	return &q_ShowSeries{}
 }
 
+// ShowSeriesCardinality returns the number of series in a database, which
+// is estimated unless exact is true
+func ShowSeriesCardinality(exact bool) Query {
+	return &q_ShowSeriesCardinality{exact: exact}
+}
+
 func ShowMeasurements() Query {
 	return &q_ShowMeasurements{}
 }
@@ -121,6 +259,108 @@ func Select(measurements ...*Measurement) Query {
 	return &q_Select{measurement: measurements}
 }
 
+func ShowQueries() Query {
+	return &q_ShowQueries{}
+}
+
+func KillQuery(qid uint64) Query {
+	return &q_KillQuery{qid: qid}
+}
+
+func ShowShards() Query {
+	return &q_ShowShards{}
+}
+
+func ShowShardGroups() Query {
+	return &q_ShowShardGroups{}
+}
+
+// DropShard drops a shard and all the data it contains
+func DropShard(id uint64) Query {
+	return &q_DropShard{id: id}
+}
+
+// Raw returns a query for one or more InfluxQL statements, separated by
+// semicolons, which are sent to the server unchanged
+func Raw(statement string) Query {
+	return &q_Raw{statement: statement}
+}
+
+// CreateContinuousQuery creates a continuous query on a database, which
+// runs the query periodically
+func CreateContinuousQuery(database, name string, query Query) Query {
+	return &q_CreateContinuousQuery{database: database, name: name, query: query}
+}
+
+// DownsampleAll returns a query for a continuous query which writes the
+// mean of every field of every measurement in a retention policy into
+// another retention policy, for each interval, keeping measurement names
+// and tags
+func DownsampleAll(database, from, into string, every time.Duration) Query {
+	return &q_Raw{statement: "SELECT mean(*) INTO " + Quote(database) + "." + Quote(into) + ".:MEASUREMENT FROM " +
+		Quote(database) + "." + Quote(from) + "./.*/ GROUP BY time(" + formatDuration(every) + "),*"}
+}
+
+// ShowDiagnostics returns the server build, runtime and configuration
+// diagnostics
+func ShowDiagnostics() Query {
+	return &q_ShowDiagnostics{}
+}
+
+// ShowStats returns server statistics for a module, such as "shard", or
+// for all modules when the module is empty
+func ShowStats(module string) Query {
+	return &q_ShowStats{module: module}
+}
+
+// ShowFieldKeys returns the field keys and types for measurements
+func ShowFieldKeys() Query {
+	return &q_ShowFieldKeys{}
+}
+
+// ShowTagKeys returns the tag keys for measurements
+func ShowTagKeys() Query {
+	return &q_ShowTagKeys{}
+}
+
+// ShowTagValues returns the values of tag keys for measurements, or the
+// values of all tag keys when no keys are given
+func ShowTagValues(keys ...string) Query {
+	return &q_ShowTagValues{keys: keys}
+}
+
+// DropMeasurement drops a measurement in the current database
+func DropMeasurement(name string) Query {
+	return &q_DropMeasurement{name: name}
+}
+
+// DropSeries drops all series in a measurement, or the series which
+// match a filter
+func DropSeries(measurement *Measurement) Query {
+	return &q_DropSeries{measurement: measurement}
+}
+
+// ShowSubscriptions returns the subscriptions for each database
+func ShowSubscriptions() Query {
+	return &q_ShowSubscriptions{}
+}
+
+// ShowUsers returns the users and whether each is an admin user
+func ShowUsers() Query {
+	return &q_ShowUsers{}
+}
+
+// ShowGrants returns the database privileges granted to a user
+func ShowGrants(user string) Query {
+	return &q_ShowGrants{user: user}
+}
+
+// CreateUser creates a user with a password, with all privileges when
+// admin is true
+func CreateUser(user, password string, admin bool) Query {
+	return &q_CreateUser{user: user, password: password, admin: admin}
+}
+
 ///////////////////////////////////////////////////////////////////////////////
 // CONSTRUCT PREDICATES
 
@@ -136,6 +376,110 @@ func TagMatches(name, regexp string) Predicate {
 	return &p_TagClause{name: name, value: []string{regexp}, op: "=~"}
 }
 
+// WhereTagRegex matches a tag against a regular expression, escaping any
+// slashes in the pattern. Returns ErrBadParameter if the pattern is not a
+// valid regular expression
+func WhereTagRegex(key, pattern string) (Predicate, error) {
+	if _, err := regexp.Compile(pattern); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadParameter, err)
+	}
+	return &p_TagRegex{name: key, pattern: pattern, op: "=~"}, nil
+}
+
+// WhereSince matches points with a timestamp within a duration of now.
+// Returns ErrBadParameter if the duration is negative
+func WhereSince(d time.Duration) (Predicate, error) {
+	if d < 0 {
+		return nil, fmt.Errorf("%w: negative duration %v", ErrBadParameter, d)
+	}
+	return &p_TimeClause{op: ">", offset: d}, nil
+}
+
+// WhereBetweenNow matches points with a timestamp between two durations
+// before now. Returns ErrBadParameter if either duration is negative or
+// start is not further in the past than end, which would match nothing
+func WhereBetweenNow(start, end time.Duration) (Predicate, error) {
+	if start < 0 || end < 0 {
+		return nil, fmt.Errorf("%w: negative duration", ErrBadParameter)
+	} else if start <= end {
+		return nil, fmt.Errorf("%w: start %v is not before end %v", ErrBadParameter, start, end)
+	}
+	return &p_TimeRange{start: start, end: end}, nil
+}
+
+// Equals matches a field or tag which is equal to a value. Strings are
+// rendered as quoted literals, and numbers, booleans, times and durations
+// in their InfluxQL forms
+func Equals(name string, value interface{}) Predicate {
+	return &p_Compare{name: name, op: "=", value: value}
+}
+
+// NotEquals matches a field or tag which is not equal to a value
+func NotEquals(name string, value interface{}) Predicate {
+	return &p_Compare{name: name, op: "!=", value: value}
+}
+
+// LessThan matches a field which is less than a value
+func LessThan(name string, value interface{}) Predicate {
+	return &p_Compare{name: name, op: "<", value: value}
+}
+
+// GreaterThan matches a field which is greater than a value
+func GreaterThan(name string, value interface{}) Predicate {
+	return &p_Compare{name: name, op: ">", value: value}
+}
+
+// And matches when all of the predicates match, and is rendered in
+// parentheses so that it can be combined with other predicates
+func And(values ...Predicate) Predicate {
+	return &p_Logical{op: "AND", values: values}
+}
+
+// Or matches when any of the predicates match, and is rendered in
+// parentheses so that it can be combined with other predicates
+func Or(values ...Predicate) Predicate {
+	return &p_Logical{op: "OR", values: values}
+}
+
+// Not matches when the predicate does not match. InfluxQL has no NOT
+// operator, so comparisons are inverted and And and Or are negated by De
+// Morgan's laws. Other predicates are rendered as NOT (...) which the
+// server will reject
+func Not(value Predicate) Predicate {
+	if value, ok := value.(negater); ok {
+		return value.negate()
+	}
+	return &p_Not{value: value}
+}
+
+func Mean(field string) Predicate {
+	return &p_Function{name: "mean", field: field}
+}
+
+func Max(field string) Predicate {
+	return &p_Function{name: "max", field: field}
+}
+
+func Min(field string) Predicate {
+	return &p_Function{name: "min", field: field}
+}
+
+func Sum(field string) Predicate {
+	return &p_Function{name: "sum", field: field}
+}
+
+func Count(field string) Predicate {
+	return &p_Function{name: "count", field: field}
+}
+
+func Last(field string) Predicate {
+	return &p_Function{name: "last", field: field}
+}
+
+func Percentile(field string, p float64) Predicate {
+	return &p_Function{name: "percentile", field: field, args: []string{strconv.FormatFloat(p, 'f', -1, 64)}}
+}
+
 ///////////////////////////////////////////////////////////////////////////////
 // SET DATABASE
 
@@ -149,6 +493,25 @@ func (q *q_CreateRetentionPolicy) Database(value string) Query { q.database = va
 func (q *q_DropRetentionPolicy) Database(value string) Query   { q.database = value; return q }
 func (q *q_AlterRetentionPolicy) Database(value string) Query  { q.database = value; return q }
 func (q *q_Select) Database(value string) Query                { return q }
+func (q *q_ShowQueries) Database(value string) Query           { return q }
+func (q *q_KillQuery) Database(value string) Query             { return q }
+func (q *q_ShowShards) Database(value string) Query            { return q }
+func (q *q_ShowShardGroups) Database(value string) Query       { return q }
+func (q *q_ShowFieldKeys) Database(value string) Query         { q.database = value; return q }
+func (q *q_ShowTagKeys) Database(value string) Query           { q.database = value; return q }
+func (q *q_ShowUsers) Database(value string) Query             { return q }
+func (q *q_ShowGrants) Database(value string) Query            { return q }
+func (q *q_DropSeries) Database(value string) Query            { return q }
+func (q *q_ShowSubscriptions) Database(value string) Query     { return q }
+func (q *q_DropMeasurement) Database(value string) Query       { return q }
+func (q *q_ShowStats) Database(value string) Query             { return q }
+func (q *q_ShowDiagnostics) Database(value string) Query       { return q }
+func (q *q_DropShard) Database(value string) Query             { return q }
+func (q *q_Raw) Database(value string) Query                   { return q }
+func (q *q_CreateContinuousQuery) Database(value string) Query { return q }
+func (q *q_ShowSeriesCardinality) Database(value string) Query { q.database = value; return q }
+func (q *q_CreateUser) Database(value string) Query            { return q }
+func (q *q_ShowTagValues) Database(value string) Query         { q.database = value; return q }
 
 ///////////////////////////////////////////////////////////////////////////////
 // SET RETENTION POLICY
@@ -168,7 +531,26 @@ func (q *q_AlterRetentionPolicy) RetentionPolicy(value *RetentionPolicy) Query {
 	q.policy = value
 	return q
 }
-func (q *q_Select) RetentionPolicy(value *RetentionPolicy) Query { return q }
+func (q *q_Select) RetentionPolicy(value *RetentionPolicy) Query                { return q }
+func (q *q_ShowQueries) RetentionPolicy(value *RetentionPolicy) Query           { return q }
+func (q *q_KillQuery) RetentionPolicy(value *RetentionPolicy) Query             { return q }
+func (q *q_ShowShards) RetentionPolicy(value *RetentionPolicy) Query            { return q }
+func (q *q_ShowShardGroups) RetentionPolicy(value *RetentionPolicy) Query       { return q }
+func (q *q_ShowFieldKeys) RetentionPolicy(value *RetentionPolicy) Query         { return q }
+func (q *q_ShowTagKeys) RetentionPolicy(value *RetentionPolicy) Query           { return q }
+func (q *q_ShowUsers) RetentionPolicy(value *RetentionPolicy) Query             { return q }
+func (q *q_ShowGrants) RetentionPolicy(value *RetentionPolicy) Query            { return q }
+func (q *q_DropSeries) RetentionPolicy(value *RetentionPolicy) Query            { return q }
+func (q *q_ShowSubscriptions) RetentionPolicy(value *RetentionPolicy) Query     { return q }
+func (q *q_DropMeasurement) RetentionPolicy(value *RetentionPolicy) Query       { return q }
+func (q *q_ShowStats) RetentionPolicy(value *RetentionPolicy) Query             { return q }
+func (q *q_ShowDiagnostics) RetentionPolicy(value *RetentionPolicy) Query       { return q }
+func (q *q_DropShard) RetentionPolicy(value *RetentionPolicy) Query             { return q }
+func (q *q_Raw) RetentionPolicy(value *RetentionPolicy) Query                   { return q }
+func (q *q_CreateContinuousQuery) RetentionPolicy(value *RetentionPolicy) Query { return q }
+func (q *q_ShowSeriesCardinality) RetentionPolicy(value *RetentionPolicy) Query { return q }
+func (q *q_CreateUser) RetentionPolicy(value *RetentionPolicy) Query            { return q }
+func (q *q_ShowTagValues) RetentionPolicy(value *RetentionPolicy) Query         { return q }
 
 ///////////////////////////////////////////////////////////////////////////////
 // SET DEFAULT
@@ -183,6 +565,25 @@ func (q *q_CreateRetentionPolicy) Default(value bool) Query { q.defalt = true; r
 func (q *q_DropRetentionPolicy) Default(value bool) Query   { return q }
 func (q *q_AlterRetentionPolicy) Default(value bool) Query  { q.defalt = true; return q }
 func (q *q_Select) Default(value bool) Query                { return q }
+func (q *q_ShowQueries) Default(value bool) Query           { return q }
+func (q *q_KillQuery) Default(value bool) Query             { return q }
+func (q *q_ShowShards) Default(value bool) Query            { return q }
+func (q *q_ShowShardGroups) Default(value bool) Query       { return q }
+func (q *q_ShowFieldKeys) Default(value bool) Query         { return q }
+func (q *q_ShowTagKeys) Default(value bool) Query           { return q }
+func (q *q_ShowUsers) Default(value bool) Query             { return q }
+func (q *q_ShowGrants) Default(value bool) Query            { return q }
+func (q *q_DropSeries) Default(value bool) Query            { return q }
+func (q *q_ShowSubscriptions) Default(value bool) Query     { return q }
+func (q *q_DropMeasurement) Default(value bool) Query       { return q }
+func (q *q_ShowStats) Default(value bool) Query             { return q }
+func (q *q_ShowDiagnostics) Default(value bool) Query       { return q }
+func (q *q_DropShard) Default(value bool) Query             { return q }
+func (q *q_Raw) Default(value bool) Query                   { return q }
+func (q *q_CreateContinuousQuery) Default(value bool) Query { return q }
+func (q *q_ShowSeriesCardinality) Default(value bool) Query { return q }
+func (q *q_CreateUser) Default(value bool) Query            { return q }
+func (q *q_ShowTagValues) Default(value bool) Query         { return q }
 
 ///////////////////////////////////////////////////////////////////////////////
 // SET OFFSET AND LIMIT
@@ -209,6 +610,58 @@ func (q *q_Select) OffsetLimit(offset uint, limit uint) Query {
 	q.limit = limit
 	return q
 }
+func (q *q_Select) SeriesOffsetLimit(offset uint, limit uint) Query {
+	q.soffset = offset
+	q.slimit = limit
+	return q
+}
+func (q *q_ShowQueries) OffsetLimit(offset uint, limit uint) Query                 { return q }
+func (q *q_KillQuery) OffsetLimit(offset uint, limit uint) Query                   { return q }
+func (q *q_ShowShards) OffsetLimit(offset uint, limit uint) Query                  { return q }
+func (q *q_ShowShardGroups) OffsetLimit(offset uint, limit uint) Query             { return q }
+func (q *q_ShowFieldKeys) OffsetLimit(offset uint, limit uint) Query               { return q }
+func (q *q_ShowTagKeys) OffsetLimit(offset uint, limit uint) Query                 { return q }
+func (q *q_ShowUsers) OffsetLimit(offset uint, limit uint) Query                   { return q }
+func (q *q_ShowGrants) OffsetLimit(offset uint, limit uint) Query                  { return q }
+func (q *q_DropSeries) OffsetLimit(offset uint, limit uint) Query                  { return q }
+func (q *q_ShowSubscriptions) OffsetLimit(offset uint, limit uint) Query           { return q }
+func (q *q_DropMeasurement) OffsetLimit(offset uint, limit uint) Query             { return q }
+func (q *q_ShowStats) OffsetLimit(offset uint, limit uint) Query                   { return q }
+func (q *q_ShowDiagnostics) OffsetLimit(offset uint, limit uint) Query             { return q }
+func (q *q_DropShard) OffsetLimit(offset uint, limit uint) Query                   { return q }
+func (q *q_Raw) OffsetLimit(offset uint, limit uint) Query                         { return q }
+func (q *q_CreateContinuousQuery) OffsetLimit(offset uint, limit uint) Query       { return q }
+func (q *q_ShowSeriesCardinality) OffsetLimit(offset uint, limit uint) Query       { return q }
+func (q *q_CreateDatabase) SeriesOffsetLimit(offset uint, limit uint) Query        { return q }
+func (q *q_DropDatabase) SeriesOffsetLimit(offset uint, limit uint) Query          { return q }
+func (q *q_ShowDatabases) SeriesOffsetLimit(offset uint, limit uint) Query         { return q }
+func (q *q_ShowRetentionPolicies) SeriesOffsetLimit(offset uint, limit uint) Query { return q }
+func (q *q_ShowSeries) SeriesOffsetLimit(offset uint, limit uint) Query            { return q }
+func (q *q_ShowMeasurements) SeriesOffsetLimit(offset uint, limit uint) Query      { return q }
+func (q *q_CreateRetentionPolicy) SeriesOffsetLimit(offset uint, limit uint) Query { return q }
+func (q *q_DropRetentionPolicy) SeriesOffsetLimit(offset uint, limit uint) Query   { return q }
+func (q *q_AlterRetentionPolicy) SeriesOffsetLimit(offset uint, limit uint) Query  { return q }
+func (q *q_ShowQueries) SeriesOffsetLimit(offset uint, limit uint) Query           { return q }
+func (q *q_KillQuery) SeriesOffsetLimit(offset uint, limit uint) Query             { return q }
+func (q *q_ShowShards) SeriesOffsetLimit(offset uint, limit uint) Query            { return q }
+func (q *q_ShowShardGroups) SeriesOffsetLimit(offset uint, limit uint) Query       { return q }
+func (q *q_ShowFieldKeys) SeriesOffsetLimit(offset uint, limit uint) Query         { return q }
+func (q *q_ShowTagKeys) SeriesOffsetLimit(offset uint, limit uint) Query           { return q }
+func (q *q_ShowUsers) SeriesOffsetLimit(offset uint, limit uint) Query             { return q }
+func (q *q_ShowGrants) SeriesOffsetLimit(offset uint, limit uint) Query            { return q }
+func (q *q_DropSeries) SeriesOffsetLimit(offset uint, limit uint) Query            { return q }
+func (q *q_ShowSubscriptions) SeriesOffsetLimit(offset uint, limit uint) Query     { return q }
+func (q *q_DropMeasurement) SeriesOffsetLimit(offset uint, limit uint) Query       { return q }
+func (q *q_ShowStats) SeriesOffsetLimit(offset uint, limit uint) Query             { return q }
+func (q *q_ShowDiagnostics) SeriesOffsetLimit(offset uint, limit uint) Query       { return q }
+func (q *q_DropShard) SeriesOffsetLimit(offset uint, limit uint) Query             { return q }
+func (q *q_Raw) SeriesOffsetLimit(offset uint, limit uint) Query                   { return q }
+func (q *q_CreateContinuousQuery) SeriesOffsetLimit(offset uint, limit uint) Query { return q }
+func (q *q_ShowSeriesCardinality) SeriesOffsetLimit(offset uint, limit uint) Query { return q }
+func (q *q_CreateUser) OffsetLimit(offset uint, limit uint) Query                  { return q }
+func (q *q_CreateUser) SeriesOffsetLimit(offset uint, limit uint) Query            { return q }
+func (q *q_ShowTagValues) OffsetLimit(offset uint, limit uint) Query               { return q }
+func (q *q_ShowTagValues) SeriesOffsetLimit(offset uint, limit uint) Query         { return q }
 
 ///////////////////////////////////////////////////////////////////////////////
 // MEASUREMENT
@@ -230,6 +683,7 @@ func (q *q_ShowSeries) Measurement(value ...*Measurement) Query {
 }
 func (q *q_Select) Measurement(value ...*Measurement) Query {
 	q.measurement = value
+	q.from = nil
 	return q
 }
 func (q *q_ShowMeasurements) Measurement(value ...*Measurement) Query {
@@ -240,6 +694,170 @@ func (q *q_ShowMeasurements) Measurement(value ...*Measurement) Query {
 	}
 	return q
 }
+func (q *q_ShowQueries) Measurement(value ...*Measurement) Query     { return q }
+func (q *q_KillQuery) Measurement(value ...*Measurement) Query       { return q }
+func (q *q_ShowShards) Measurement(value ...*Measurement) Query      { return q }
+func (q *q_ShowShardGroups) Measurement(value ...*Measurement) Query { return q }
+func (q *q_ShowFieldKeys) Measurement(value ...*Measurement) Query {
+	if len(value) > 0 {
+		q.measurement = value[0]
+	} else {
+		q.measurement = nil
+	}
+	return q
+}
+func (q *q_ShowTagKeys) Measurement(value ...*Measurement) Query {
+	if len(value) > 0 {
+		q.measurement = value[0]
+	} else {
+		q.measurement = nil
+	}
+	return q
+}
+func (q *q_ShowUsers) Measurement(value ...*Measurement) Query             { return q }
+func (q *q_ShowGrants) Measurement(value ...*Measurement) Query            { return q }
+func (q *q_DropSeries) Measurement(value ...*Measurement) Query            { return q }
+func (q *q_ShowSubscriptions) Measurement(value ...*Measurement) Query     { return q }
+func (q *q_DropMeasurement) Measurement(value ...*Measurement) Query       { return q }
+func (q *q_ShowStats) Measurement(value ...*Measurement) Query             { return q }
+func (q *q_ShowDiagnostics) Measurement(value ...*Measurement) Query       { return q }
+func (q *q_DropShard) Measurement(value ...*Measurement) Query             { return q }
+func (q *q_Raw) Measurement(value ...*Measurement) Query                   { return q }
+func (q *q_CreateContinuousQuery) Measurement(value ...*Measurement) Query { return q }
+func (q *q_ShowSeriesCardinality) Measurement(value ...*Measurement) Query {
+	if len(value) > 0 {
+		q.measurement = value[0]
+	} else {
+		q.measurement = nil
+	}
+	return q
+}
+func (q *q_CreateUser) Measurement(value ...*Measurement) Query { return q }
+func (q *q_ShowTagValues) Measurement(value ...*Measurement) Query {
+	if len(value) > 0 {
+		q.measurement = value[0]
+	} else {
+		q.measurement = nil
+	}
+	return q
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// FROM
+
+func (q *q_CreateDatabase) From(value ...string) Query                                { return q }
+func (q *q_CreateDatabase) FromRegex(pattern string) Query                            { return q }
+func (q *q_CreateDatabase) FromPolicies(measurement string, policies ...string) Query { return q }
+func (q *q_DropDatabase) From(value ...string) Query                                  { return q }
+func (q *q_DropDatabase) FromRegex(pattern string) Query                              { return q }
+func (q *q_DropDatabase) FromPolicies(measurement string, policies ...string) Query   { return q }
+func (q *q_ShowDatabases) From(value ...string) Query                                 { return q }
+func (q *q_ShowDatabases) FromRegex(pattern string) Query                             { return q }
+func (q *q_ShowDatabases) FromPolicies(measurement string, policies ...string) Query  { return q }
+func (q *q_ShowRetentionPolicies) From(value ...string) Query                         { return q }
+func (q *q_ShowRetentionPolicies) FromRegex(pattern string) Query                     { return q }
+func (q *q_ShowRetentionPolicies) FromPolicies(measurement string, policies ...string) Query {
+	return q
+}
+func (q *q_CreateRetentionPolicy) From(value ...string) Query     { return q }
+func (q *q_CreateRetentionPolicy) FromRegex(pattern string) Query { return q }
+func (q *q_CreateRetentionPolicy) FromPolicies(measurement string, policies ...string) Query {
+	return q
+}
+func (q *q_AlterRetentionPolicy) From(value ...string) Query                                { return q }
+func (q *q_AlterRetentionPolicy) FromRegex(pattern string) Query                            { return q }
+func (q *q_AlterRetentionPolicy) FromPolicies(measurement string, policies ...string) Query { return q }
+func (q *q_DropRetentionPolicy) From(value ...string) Query                                 { return q }
+func (q *q_DropRetentionPolicy) FromRegex(pattern string) Query                             { return q }
+func (q *q_DropRetentionPolicy) FromPolicies(measurement string, policies ...string) Query  { return q }
+func (q *q_ShowSeries) From(value ...string) Query                                          { return q }
+func (q *q_ShowSeries) FromRegex(pattern string) Query                                      { return q }
+func (q *q_ShowSeries) FromPolicies(measurement string, policies ...string) Query           { return q }
+func (q *q_Select) From(value ...string) Query {
+	q.measurement = nil
+	q.from = make([]string, len(value))
+	for i, name := range value {
+		q.from[i] = QuoteString(name)
+	}
+	return q
+}
+func (q *q_Select) FromRegex(pattern string) Query {
+	q.measurement = nil
+	q.from = []string{"/" + escapeRegex(pattern) + "/"}
+	return q
+}
+func (q *q_Select) FromPolicies(measurement string, policies ...string) Query {
+	q.measurement = nil
+	q.from = make([]string, len(policies))
+	for i, policy := range policies {
+		q.from[i] = QuoteString(policy) + "." + QuoteString(measurement)
+	}
+	return q
+}
+func (q *q_ShowMeasurements) From(value ...string) Query                                 { return q }
+func (q *q_ShowMeasurements) FromRegex(pattern string) Query                             { return q }
+func (q *q_ShowMeasurements) FromPolicies(measurement string, policies ...string) Query  { return q }
+func (q *q_ShowQueries) From(value ...string) Query                                      { return q }
+func (q *q_ShowQueries) FromRegex(pattern string) Query                                  { return q }
+func (q *q_ShowQueries) FromPolicies(measurement string, policies ...string) Query       { return q }
+func (q *q_KillQuery) From(value ...string) Query                                        { return q }
+func (q *q_KillQuery) FromRegex(pattern string) Query                                    { return q }
+func (q *q_KillQuery) FromPolicies(measurement string, policies ...string) Query         { return q }
+func (q *q_ShowShards) From(value ...string) Query                                       { return q }
+func (q *q_ShowShards) FromRegex(pattern string) Query                                   { return q }
+func (q *q_ShowShards) FromPolicies(measurement string, policies ...string) Query        { return q }
+func (q *q_ShowShardGroups) From(value ...string) Query                                  { return q }
+func (q *q_ShowShardGroups) FromRegex(pattern string) Query                              { return q }
+func (q *q_ShowShardGroups) FromPolicies(measurement string, policies ...string) Query   { return q }
+func (q *q_ShowFieldKeys) From(value ...string) Query                                    { return q }
+func (q *q_ShowFieldKeys) FromRegex(pattern string) Query                                { return q }
+func (q *q_ShowFieldKeys) FromPolicies(measurement string, policies ...string) Query     { return q }
+func (q *q_ShowTagKeys) From(value ...string) Query                                      { return q }
+func (q *q_ShowTagKeys) FromRegex(pattern string) Query                                  { return q }
+func (q *q_ShowTagKeys) FromPolicies(measurement string, policies ...string) Query       { return q }
+func (q *q_ShowUsers) From(value ...string) Query                                        { return q }
+func (q *q_ShowUsers) FromRegex(pattern string) Query                                    { return q }
+func (q *q_ShowUsers) FromPolicies(measurement string, policies ...string) Query         { return q }
+func (q *q_ShowGrants) From(value ...string) Query                                       { return q }
+func (q *q_ShowGrants) FromRegex(pattern string) Query                                   { return q }
+func (q *q_ShowGrants) FromPolicies(measurement string, policies ...string) Query        { return q }
+func (q *q_DropSeries) From(value ...string) Query                                       { return q }
+func (q *q_DropSeries) FromRegex(pattern string) Query                                   { return q }
+func (q *q_DropSeries) FromPolicies(measurement string, policies ...string) Query        { return q }
+func (q *q_ShowSubscriptions) From(value ...string) Query                                { return q }
+func (q *q_ShowSubscriptions) FromRegex(pattern string) Query                            { return q }
+func (q *q_ShowSubscriptions) FromPolicies(measurement string, policies ...string) Query { return q }
+func (q *q_DropMeasurement) From(value ...string) Query                                  { return q }
+func (q *q_DropMeasurement) FromRegex(pattern string) Query                              { return q }
+func (q *q_DropMeasurement) FromPolicies(measurement string, policies ...string) Query   { return q }
+func (q *q_ShowStats) From(value ...string) Query                                        { return q }
+func (q *q_ShowStats) FromRegex(pattern string) Query                                    { return q }
+func (q *q_ShowStats) FromPolicies(measurement string, policies ...string) Query         { return q }
+func (q *q_ShowDiagnostics) From(value ...string) Query                                  { return q }
+func (q *q_ShowDiagnostics) FromRegex(pattern string) Query                              { return q }
+func (q *q_ShowDiagnostics) FromPolicies(measurement string, policies ...string) Query   { return q }
+func (q *q_DropShard) From(value ...string) Query                                        { return q }
+func (q *q_DropShard) FromRegex(pattern string) Query                                    { return q }
+func (q *q_DropShard) FromPolicies(measurement string, policies ...string) Query         { return q }
+func (q *q_Raw) From(value ...string) Query                                              { return q }
+func (q *q_Raw) FromRegex(pattern string) Query                                          { return q }
+func (q *q_Raw) FromPolicies(measurement string, policies ...string) Query               { return q }
+func (q *q_CreateContinuousQuery) From(value ...string) Query                            { return q }
+func (q *q_CreateContinuousQuery) FromRegex(pattern string) Query                        { return q }
+func (q *q_CreateContinuousQuery) FromPolicies(measurement string, policies ...string) Query {
+	return q
+}
+func (q *q_ShowSeriesCardinality) From(value ...string) Query     { return q }
+func (q *q_ShowSeriesCardinality) FromRegex(pattern string) Query { return q }
+func (q *q_ShowSeriesCardinality) FromPolicies(measurement string, policies ...string) Query {
+	return q
+}
+func (q *q_CreateUser) From(value ...string) Query                                   { return q }
+func (q *q_CreateUser) FromRegex(pattern string) Query                               { return q }
+func (q *q_CreateUser) FromPolicies(measurement string, policies ...string) Query    { return q }
+func (q *q_ShowTagValues) From(value ...string) Query                                { return q }
+func (q *q_ShowTagValues) FromRegex(pattern string) Query                            { return q }
+func (q *q_ShowTagValues) FromPolicies(measurement string, policies ...string) Query { return q }
 
 ///////////////////////////////////////////////////////////////////////////////
 // FILTER
@@ -257,6 +875,319 @@ func (q *q_Select) Filter(value ...Predicate) Query {
 	q.where = value
 	return q
 }
+func (q *q_Select) Where(value Predicate) Query {
+	if value == nil {
+		q.where = nil
+	} else {
+		q.where = []Predicate{value}
+	}
+	return q
+}
+func (q *q_ShowQueries) Filter(value ...Predicate) Query       { return q }
+func (q *q_KillQuery) Filter(value ...Predicate) Query         { return q }
+func (q *q_ShowShards) Filter(value ...Predicate) Query        { return q }
+func (q *q_ShowShardGroups) Filter(value ...Predicate) Query   { return q }
+func (q *q_ShowFieldKeys) Filter(value ...Predicate) Query     { return q }
+func (q *q_ShowTagKeys) Filter(value ...Predicate) Query       { return q }
+func (q *q_ShowDatabases) Where(value Predicate) Query         { return q }
+func (q *q_CreateDatabase) Where(value Predicate) Query        { return q }
+func (q *q_DropDatabase) Where(value Predicate) Query          { return q }
+func (q *q_DropRetentionPolicy) Where(value Predicate) Query   { return q }
+func (q *q_AlterRetentionPolicy) Where(value Predicate) Query  { return q }
+func (q *q_ShowRetentionPolicies) Where(value Predicate) Query { return q }
+func (q *q_CreateRetentionPolicy) Where(value Predicate) Query { return q }
+func (q *q_ShowSeries) Where(value Predicate) Query            { return q }
+func (q *q_ShowMeasurements) Where(value Predicate) Query      { return q }
+func (q *q_ShowQueries) Where(value Predicate) Query           { return q }
+func (q *q_KillQuery) Where(value Predicate) Query             { return q }
+func (q *q_ShowShards) Where(value Predicate) Query            { return q }
+func (q *q_ShowShardGroups) Where(value Predicate) Query       { return q }
+func (q *q_ShowFieldKeys) Where(value Predicate) Query         { return q }
+func (q *q_ShowTagKeys) Where(value Predicate) Query           { return q }
+func (q *q_ShowUsers) Filter(value ...Predicate) Query         { return q }
+func (q *q_ShowGrants) Filter(value ...Predicate) Query        { return q }
+func (q *q_ShowUsers) Where(value Predicate) Query             { return q }
+func (q *q_ShowGrants) Where(value Predicate) Query            { return q }
+func (q *q_DropSeries) Filter(value ...Predicate) Query {
+	q.where = value
+	return q
+}
+func (q *q_DropSeries) Where(value Predicate) Query {
+	q.where = []Predicate{value}
+	return q
+}
+func (q *q_ShowSubscriptions) Filter(value ...Predicate) Query     { return q }
+func (q *q_ShowSubscriptions) Where(value Predicate) Query         { return q }
+func (q *q_DropMeasurement) Filter(value ...Predicate) Query       { return q }
+func (q *q_DropMeasurement) Where(value Predicate) Query           { return q }
+func (q *q_ShowStats) Filter(value ...Predicate) Query             { return q }
+func (q *q_ShowStats) Where(value Predicate) Query                 { return q }
+func (q *q_ShowDiagnostics) Filter(value ...Predicate) Query       { return q }
+func (q *q_ShowDiagnostics) Where(value Predicate) Query           { return q }
+func (q *q_DropShard) Filter(value ...Predicate) Query             { return q }
+func (q *q_DropShard) Where(value Predicate) Query                 { return q }
+func (q *q_Raw) Filter(value ...Predicate) Query                   { return q }
+func (q *q_Raw) Where(value Predicate) Query                       { return q }
+func (q *q_CreateContinuousQuery) Filter(value ...Predicate) Query { return q }
+func (q *q_CreateContinuousQuery) Where(value Predicate) Query     { return q }
+func (q *q_ShowSeriesCardinality) Filter(value ...Predicate) Query { return q }
+func (q *q_ShowSeriesCardinality) Where(value Predicate) Query     { return q }
+func (q *q_CreateUser) Filter(value ...Predicate) Query            { return q }
+func (q *q_CreateUser) Where(value Predicate) Query                { return q }
+func (q *q_ShowTagValues) Filter(value ...Predicate) Query         { return q }
+func (q *q_ShowTagValues) Where(value Predicate) Query             { return q }
+
+///////////////////////////////////////////////////////////////////////////////
+// COLUMNS
+
+func (q *q_CreateDatabase) Columns(value ...Predicate) Query        { return q }
+func (q *q_DropDatabase) Columns(value ...Predicate) Query          { return q }
+func (q *q_ShowDatabases) Columns(value ...Predicate) Query         { return q }
+func (q *q_ShowRetentionPolicies) Columns(value ...Predicate) Query { return q }
+func (q *q_CreateRetentionPolicy) Columns(value ...Predicate) Query { return q }
+func (q *q_AlterRetentionPolicy) Columns(value ...Predicate) Query  { return q }
+func (q *q_DropRetentionPolicy) Columns(value ...Predicate) Query   { return q }
+func (q *q_ShowSeries) Columns(value ...Predicate) Query            { return q }
+func (q *q_ShowMeasurements) Columns(value ...Predicate) Query      { return q }
+func (q *q_ShowQueries) Columns(value ...Predicate) Query           { return q }
+func (q *q_KillQuery) Columns(value ...Predicate) Query             { return q }
+func (q *q_Select) Columns(value ...Predicate) Query {
+	q.columns = value
+	return q
+}
+func (q *q_ShowShards) Columns(value ...Predicate) Query            { return q }
+func (q *q_ShowShardGroups) Columns(value ...Predicate) Query       { return q }
+func (q *q_ShowFieldKeys) Columns(value ...Predicate) Query         { return q }
+func (q *q_ShowTagKeys) Columns(value ...Predicate) Query           { return q }
+func (q *q_ShowUsers) Columns(value ...Predicate) Query             { return q }
+func (q *q_ShowGrants) Columns(value ...Predicate) Query            { return q }
+func (q *q_DropSeries) Columns(value ...Predicate) Query            { return q }
+func (q *q_ShowSubscriptions) Columns(value ...Predicate) Query     { return q }
+func (q *q_DropMeasurement) Columns(value ...Predicate) Query       { return q }
+func (q *q_ShowStats) Columns(value ...Predicate) Query             { return q }
+func (q *q_ShowDiagnostics) Columns(value ...Predicate) Query       { return q }
+func (q *q_DropShard) Columns(value ...Predicate) Query             { return q }
+func (q *q_Raw) Columns(value ...Predicate) Query                   { return q }
+func (q *q_CreateContinuousQuery) Columns(value ...Predicate) Query { return q }
+func (q *q_ShowSeriesCardinality) Columns(value ...Predicate) Query { return q }
+func (q *q_CreateUser) Columns(value ...Predicate) Query            { return q }
+func (q *q_ShowTagValues) Columns(value ...Predicate) Query         { return q }
+
+///////////////////////////////////////////////////////////////////////////////
+// INTO
+
+func (q *q_ShowDatabases) Into(value *Measurement) Query         { return q }
+func (q *q_CreateDatabase) Into(value *Measurement) Query        { return q }
+func (q *q_DropDatabase) Into(value *Measurement) Query          { return q }
+func (q *q_DropRetentionPolicy) Into(value *Measurement) Query   { return q }
+func (q *q_AlterRetentionPolicy) Into(value *Measurement) Query  { return q }
+func (q *q_ShowRetentionPolicies) Into(value *Measurement) Query { return q }
+func (q *q_CreateRetentionPolicy) Into(value *Measurement) Query { return q }
+func (q *q_ShowSeries) Into(value *Measurement) Query            { return q }
+func (q *q_ShowMeasurements) Into(value *Measurement) Query      { return q }
+func (q *q_ShowQueries) Into(value *Measurement) Query           { return q }
+func (q *q_KillQuery) Into(value *Measurement) Query             { return q }
+func (q *q_Select) Into(value *Measurement) Query {
+	q.into = value
+	return q
+}
+func (q *q_ShowShards) Into(value *Measurement) Query            { return q }
+func (q *q_ShowShardGroups) Into(value *Measurement) Query       { return q }
+func (q *q_ShowFieldKeys) Into(value *Measurement) Query         { return q }
+func (q *q_ShowTagKeys) Into(value *Measurement) Query           { return q }
+func (q *q_ShowUsers) Into(value *Measurement) Query             { return q }
+func (q *q_ShowGrants) Into(value *Measurement) Query            { return q }
+func (q *q_DropSeries) Into(value *Measurement) Query            { return q }
+func (q *q_ShowSubscriptions) Into(value *Measurement) Query     { return q }
+func (q *q_DropMeasurement) Into(value *Measurement) Query       { return q }
+func (q *q_ShowStats) Into(value *Measurement) Query             { return q }
+func (q *q_ShowDiagnostics) Into(value *Measurement) Query       { return q }
+func (q *q_DropShard) Into(value *Measurement) Query             { return q }
+func (q *q_Raw) Into(value *Measurement) Query                   { return q }
+func (q *q_CreateContinuousQuery) Into(value *Measurement) Query { return q }
+func (q *q_ShowSeriesCardinality) Into(value *Measurement) Query { return q }
+func (q *q_CreateUser) Into(value *Measurement) Query            { return q }
+func (q *q_ShowTagValues) Into(value *Measurement) Query         { return q }
+
+///////////////////////////////////////////////////////////////////////////////
+// GROUP BY
+
+func (q *q_ShowDatabases) GroupBy(value ...string) Query                                  { return q }
+func (q *q_ShowDatabases) GroupByTime(interval time.Duration) Query                       { return q }
+func (q *q_ShowDatabases) GroupByTimeOffset(interval, offset time.Duration) Query         { return q }
+func (q *q_CreateDatabase) GroupBy(value ...string) Query                                 { return q }
+func (q *q_CreateDatabase) GroupByTime(interval time.Duration) Query                      { return q }
+func (q *q_CreateDatabase) GroupByTimeOffset(interval, offset time.Duration) Query        { return q }
+func (q *q_DropDatabase) GroupBy(value ...string) Query                                   { return q }
+func (q *q_DropDatabase) GroupByTime(interval time.Duration) Query                        { return q }
+func (q *q_DropDatabase) GroupByTimeOffset(interval, offset time.Duration) Query          { return q }
+func (q *q_DropRetentionPolicy) GroupBy(value ...string) Query                            { return q }
+func (q *q_DropRetentionPolicy) GroupByTime(interval time.Duration) Query                 { return q }
+func (q *q_DropRetentionPolicy) GroupByTimeOffset(interval, offset time.Duration) Query   { return q }
+func (q *q_AlterRetentionPolicy) GroupBy(value ...string) Query                           { return q }
+func (q *q_AlterRetentionPolicy) GroupByTime(interval time.Duration) Query                { return q }
+func (q *q_AlterRetentionPolicy) GroupByTimeOffset(interval, offset time.Duration) Query  { return q }
+func (q *q_ShowRetentionPolicies) GroupBy(value ...string) Query                          { return q }
+func (q *q_ShowRetentionPolicies) GroupByTime(interval time.Duration) Query               { return q }
+func (q *q_ShowRetentionPolicies) GroupByTimeOffset(interval, offset time.Duration) Query { return q }
+func (q *q_CreateRetentionPolicy) GroupBy(value ...string) Query                          { return q }
+func (q *q_CreateRetentionPolicy) GroupByTime(interval time.Duration) Query               { return q }
+func (q *q_CreateRetentionPolicy) GroupByTimeOffset(interval, offset time.Duration) Query { return q }
+func (q *q_ShowSeries) GroupBy(value ...string) Query                                     { return q }
+func (q *q_ShowSeries) GroupByTime(interval time.Duration) Query                          { return q }
+func (q *q_ShowSeries) GroupByTimeOffset(interval, offset time.Duration) Query            { return q }
+func (q *q_ShowMeasurements) GroupBy(value ...string) Query                               { return q }
+func (q *q_ShowMeasurements) GroupByTime(interval time.Duration) Query                    { return q }
+func (q *q_ShowMeasurements) GroupByTimeOffset(interval, offset time.Duration) Query      { return q }
+func (q *q_ShowQueries) GroupBy(value ...string) Query                                    { return q }
+func (q *q_ShowQueries) GroupByTime(interval time.Duration) Query                         { return q }
+func (q *q_ShowQueries) GroupByTimeOffset(interval, offset time.Duration) Query           { return q }
+func (q *q_KillQuery) GroupBy(value ...string) Query                                      { return q }
+func (q *q_KillQuery) GroupByTime(interval time.Duration) Query                           { return q }
+func (q *q_KillQuery) GroupByTimeOffset(interval, offset time.Duration) Query             { return q }
+func (q *q_Select) GroupBy(value ...string) Query {
+	q.group = value
+	return q
+}
+func (q *q_Select) GroupByTime(interval time.Duration) Query {
+	return q.GroupByTimeOffset(interval, 0)
+}
+func (q *q_Select) GroupByTimeOffset(interval, offset time.Duration) Query {
+	q.interval, q.intervalOffset = interval, offset
+	return q
+}
+func (q *q_ShowShards) GroupBy(value ...string) Query                                     { return q }
+func (q *q_ShowShards) GroupByTime(interval time.Duration) Query                          { return q }
+func (q *q_ShowShards) GroupByTimeOffset(interval, offset time.Duration) Query            { return q }
+func (q *q_ShowShardGroups) GroupBy(value ...string) Query                                { return q }
+func (q *q_ShowShardGroups) GroupByTime(interval time.Duration) Query                     { return q }
+func (q *q_ShowShardGroups) GroupByTimeOffset(interval, offset time.Duration) Query       { return q }
+func (q *q_ShowFieldKeys) GroupBy(value ...string) Query                                  { return q }
+func (q *q_ShowFieldKeys) GroupByTime(interval time.Duration) Query                       { return q }
+func (q *q_ShowFieldKeys) GroupByTimeOffset(interval, offset time.Duration) Query         { return q }
+func (q *q_ShowTagKeys) GroupBy(value ...string) Query                                    { return q }
+func (q *q_ShowTagKeys) GroupByTime(interval time.Duration) Query                         { return q }
+func (q *q_ShowTagKeys) GroupByTimeOffset(interval, offset time.Duration) Query           { return q }
+func (q *q_ShowUsers) GroupBy(value ...string) Query                                      { return q }
+func (q *q_ShowUsers) GroupByTime(interval time.Duration) Query                           { return q }
+func (q *q_ShowUsers) GroupByTimeOffset(interval, offset time.Duration) Query             { return q }
+func (q *q_ShowGrants) GroupBy(value ...string) Query                                     { return q }
+func (q *q_ShowGrants) GroupByTime(interval time.Duration) Query                          { return q }
+func (q *q_ShowGrants) GroupByTimeOffset(interval, offset time.Duration) Query            { return q }
+func (q *q_DropSeries) GroupBy(value ...string) Query                                     { return q }
+func (q *q_DropSeries) GroupByTime(interval time.Duration) Query                          { return q }
+func (q *q_DropSeries) GroupByTimeOffset(interval, offset time.Duration) Query            { return q }
+func (q *q_ShowSubscriptions) GroupBy(value ...string) Query                              { return q }
+func (q *q_ShowSubscriptions) GroupByTime(interval time.Duration) Query                   { return q }
+func (q *q_ShowSubscriptions) GroupByTimeOffset(interval, offset time.Duration) Query     { return q }
+func (q *q_DropMeasurement) GroupBy(value ...string) Query                                { return q }
+func (q *q_DropMeasurement) GroupByTime(interval time.Duration) Query                     { return q }
+func (q *q_DropMeasurement) GroupByTimeOffset(interval, offset time.Duration) Query       { return q }
+func (q *q_ShowStats) GroupBy(value ...string) Query                                      { return q }
+func (q *q_ShowStats) GroupByTime(interval time.Duration) Query                           { return q }
+func (q *q_ShowStats) GroupByTimeOffset(interval, offset time.Duration) Query             { return q }
+func (q *q_ShowDiagnostics) GroupBy(value ...string) Query                                { return q }
+func (q *q_ShowDiagnostics) GroupByTime(interval time.Duration) Query                     { return q }
+func (q *q_ShowDiagnostics) GroupByTimeOffset(interval, offset time.Duration) Query       { return q }
+func (q *q_DropShard) GroupBy(value ...string) Query                                      { return q }
+func (q *q_DropShard) GroupByTime(interval time.Duration) Query                           { return q }
+func (q *q_DropShard) GroupByTimeOffset(interval, offset time.Duration) Query             { return q }
+func (q *q_Raw) GroupBy(value ...string) Query                                            { return q }
+func (q *q_Raw) GroupByTime(interval time.Duration) Query                                 { return q }
+func (q *q_Raw) GroupByTimeOffset(interval, offset time.Duration) Query                   { return q }
+func (q *q_CreateContinuousQuery) GroupBy(value ...string) Query                          { return q }
+func (q *q_CreateContinuousQuery) GroupByTime(interval time.Duration) Query               { return q }
+func (q *q_CreateContinuousQuery) GroupByTimeOffset(interval, offset time.Duration) Query { return q }
+func (q *q_ShowSeriesCardinality) GroupBy(value ...string) Query                          { return q }
+func (q *q_ShowSeriesCardinality) GroupByTime(interval time.Duration) Query               { return q }
+func (q *q_ShowSeriesCardinality) GroupByTimeOffset(interval, offset time.Duration) Query { return q }
+func (q *q_CreateUser) GroupBy(value ...string) Query                                     { return q }
+func (q *q_CreateUser) GroupByTime(interval time.Duration) Query                          { return q }
+func (q *q_CreateUser) GroupByTimeOffset(interval, offset time.Duration) Query            { return q }
+func (q *q_ShowTagValues) GroupBy(value ...string) Query                                  { return q }
+func (q *q_ShowTagValues) GroupByTime(interval time.Duration) Query                       { return q }
+func (q *q_ShowTagValues) GroupByTimeOffset(interval, offset time.Duration) Query         { return q }
+
+///////////////////////////////////////////////////////////////////////////////
+// TIME ZONE
+
+func (q *q_ShowDatabases) TZ(location string) (Query, error)         { return q, nil }
+func (q *q_CreateDatabase) TZ(location string) (Query, error)        { return q, nil }
+func (q *q_DropDatabase) TZ(location string) (Query, error)          { return q, nil }
+func (q *q_DropRetentionPolicy) TZ(location string) (Query, error)   { return q, nil }
+func (q *q_AlterRetentionPolicy) TZ(location string) (Query, error)  { return q, nil }
+func (q *q_ShowRetentionPolicies) TZ(location string) (Query, error) { return q, nil }
+func (q *q_CreateRetentionPolicy) TZ(location string) (Query, error) { return q, nil }
+func (q *q_ShowSeries) TZ(location string) (Query, error)            { return q, nil }
+func (q *q_ShowMeasurements) TZ(location string) (Query, error)      { return q, nil }
+func (q *q_ShowQueries) TZ(location string) (Query, error)           { return q, nil }
+func (q *q_KillQuery) TZ(location string) (Query, error)             { return q, nil }
+func (q *q_Select) TZ(location string) (Query, error) {
+	if _, err := time.LoadLocation(location); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadParameter, err)
+	}
+	q.tz = location
+	return q, nil
+}
+func (q *q_ShowShards) TZ(location string) (Query, error)            { return q, nil }
+func (q *q_ShowShardGroups) TZ(location string) (Query, error)       { return q, nil }
+func (q *q_ShowFieldKeys) TZ(location string) (Query, error)         { return q, nil }
+func (q *q_ShowTagKeys) TZ(location string) (Query, error)           { return q, nil }
+func (q *q_ShowUsers) TZ(location string) (Query, error)             { return q, nil }
+func (q *q_ShowGrants) TZ(location string) (Query, error)            { return q, nil }
+func (q *q_DropSeries) TZ(location string) (Query, error)            { return q, nil }
+func (q *q_ShowSubscriptions) TZ(location string) (Query, error)     { return q, nil }
+func (q *q_DropMeasurement) TZ(location string) (Query, error)       { return q, nil }
+func (q *q_ShowStats) TZ(location string) (Query, error)             { return q, nil }
+func (q *q_ShowDiagnostics) TZ(location string) (Query, error)       { return q, nil }
+func (q *q_DropShard) TZ(location string) (Query, error)             { return q, nil }
+func (q *q_Raw) TZ(location string) (Query, error)                   { return q, nil }
+func (q *q_CreateContinuousQuery) TZ(location string) (Query, error) { return q, nil }
+func (q *q_ShowSeriesCardinality) TZ(location string) (Query, error) { return q, nil }
+func (q *q_ShowTagValues) TZ(location string) (Query, error)         { return q, nil }
+
+///////////////////////////////////////////////////////////////////////////////
+// DISTINCT
+
+func (q *q_ShowDatabases) Distinct(field string) (Query, error)         { return q, nil }
+func (q *q_CreateDatabase) Distinct(field string) (Query, error)        { return q, nil }
+func (q *q_DropDatabase) Distinct(field string) (Query, error)          { return q, nil }
+func (q *q_DropRetentionPolicy) Distinct(field string) (Query, error)   { return q, nil }
+func (q *q_AlterRetentionPolicy) Distinct(field string) (Query, error)  { return q, nil }
+func (q *q_ShowRetentionPolicies) Distinct(field string) (Query, error) { return q, nil }
+func (q *q_CreateRetentionPolicy) Distinct(field string) (Query, error) { return q, nil }
+func (q *q_ShowSeries) Distinct(field string) (Query, error)            { return q, nil }
+func (q *q_ShowMeasurements) Distinct(field string) (Query, error)      { return q, nil }
+func (q *q_ShowQueries) Distinct(field string) (Query, error)           { return q, nil }
+func (q *q_KillQuery) Distinct(field string) (Query, error)             { return q, nil }
+func (q *q_Select) Distinct(field string) (Query, error) {
+	if field == "" {
+		return nil, ErrBadParameter
+	} else if len(q.columns) > 0 {
+		return nil, fmt.Errorf("%w: distinct cannot be combined with other columns", ErrBadParameter)
+	}
+	q.columns = []Predicate{&p_Function{name: "distinct", field: field}}
+	return q, nil
+}
+func (q *q_ShowShards) Distinct(field string) (Query, error)            { return q, nil }
+func (q *q_ShowShardGroups) Distinct(field string) (Query, error)       { return q, nil }
+func (q *q_ShowFieldKeys) Distinct(field string) (Query, error)         { return q, nil }
+func (q *q_ShowTagKeys) Distinct(field string) (Query, error)           { return q, nil }
+func (q *q_ShowUsers) Distinct(field string) (Query, error)             { return q, nil }
+func (q *q_ShowGrants) Distinct(field string) (Query, error)            { return q, nil }
+func (q *q_DropSeries) Distinct(field string) (Query, error)            { return q, nil }
+func (q *q_ShowSubscriptions) Distinct(field string) (Query, error)     { return q, nil }
+func (q *q_DropMeasurement) Distinct(field string) (Query, error)       { return q, nil }
+func (q *q_ShowStats) Distinct(field string) (Query, error)             { return q, nil }
+func (q *q_ShowDiagnostics) Distinct(field string) (Query, error)       { return q, nil }
+func (q *q_DropShard) Distinct(field string) (Query, error)             { return q, nil }
+func (q *q_Raw) Distinct(field string) (Query, error)                   { return q, nil }
+func (q *q_CreateContinuousQuery) Distinct(field string) (Query, error) { return q, nil }
+func (q *q_ShowSeriesCardinality) Distinct(field string) (Query, error) { return q, nil }
+func (q *q_CreateUser) TZ(location string) (Query, error)               { return q, nil }
+
+func (q *q_CreateUser) Distinct(field string) (Query, error)    { return q, nil }
+func (q *q_ShowTagValues) Distinct(field string) (Query, error) { return q, nil }
 
 ///////////////////////////////////////////////////////////////////////////////
 // STRINGIFY
@@ -294,16 +1225,146 @@ func (p *p_TagClause) String() string {
 			}
 			return Quote(p.name) + " IN (" + strings.Join(values, ",") + ")"
 		}
-	case "=~":
+	case "=~", "!~":
 		v := strings.Trim(p.value[0], "/")
 		return Quote(p.name) + " " + p.op + " /" + v + "/"
 	}
 	return Quote(p.name) + " " + p.op + " " + QuoteString(p.value[0])
 }
 
+func (p *p_TagRegex) String() string {
+	return Quote(p.name) + " " + p.op + " /" + escapeRegex(p.pattern) + "/"
+}
+
+func (p *p_Compare) String() string {
+	return Quote(p.name) + " " + p.op + " " + literal(p.value)
+}
+
+func (p *p_Logical) String() string {
+	values := make([]string, len(p.values))
+	for i, value := range p.values {
+		values[i] = value.String()
+	}
+	return "(" + strings.Join(values, " "+p.op+" ") + ")"
+}
+
+func (p *p_Not) String() string {
+	return "NOT (" + p.value.String() + ")"
+}
+
+func (p *p_TimeClause) String() string {
+	return "time " + p.op + " now() - " + formatDuration(p.offset)
+}
+
+func (p *p_TimeRange) String() string {
+	return "time > now() - " + formatDuration(p.start) + " AND time < now() - " + formatDuration(p.end)
+}
+
+func (p *p_Function) String() string {
+	field := p.field
+	if field != "*" {
+		field = Quote(field)
+	}
+	args := append([]string{field}, p.args...)
+	return p.name + "(" + strings.Join(args, ",") + ")"
+}
+
+// formatDuration returns a duration literal in the largest unit which
+// represents the duration exactly
+func formatDuration(d time.Duration) string {
+	units := []struct {
+		unit string
+		d    time.Duration
+	}{
+		{"w", time.Hour * 24 * 7}, {"d", time.Hour * 24}, {"h", time.Hour}, {"m", time.Minute},
+		{"s", time.Second}, {"ms", time.Millisecond}, {"u", time.Microsecond},
+	}
+	if d == 0 {
+		return "0s"
+	}
+	for _, u := range units {
+		if d%u.d == 0 {
+			return fmt.Sprint(int64(d/u.d)) + u.unit
+		}
+	}
+	return fmt.Sprint(int64(d)) + "ns"
+}
+
+// literal returns a value as an InfluxQL literal. Strings and times are
+// single-quoted, and other values are rendered without quotes
+func literal(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		v = strings.Replace(v, "\\", "\\\\", -1)
+		v = strings.Replace(v, "'", "\\'", -1)
+		return "'" + v + "'"
+	case bool:
+		return strconv.FormatBool(v)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(v)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return "'" + v.UTC().Format(time.RFC3339Nano) + "'"
+	case time.Duration:
+		return formatDuration(v)
+	default:
+		return literal(fmt.Sprint(v))
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// NEGATE PREDICATES
+
+var (
+	// negateOp maps each comparison operator to its inverse
+	negateOp = map[string]string{
+		"=": "!=", "!=": "=", "<": ">=", ">=": "<", ">": "<=", "<=": ">", "=~": "!~", "!~": "=~",
+	}
+)
+
+func (p *p_Compare) negate() Predicate {
+	return &p_Compare{name: p.name, op: negateOp[p.op], value: p.value}
+}
+
+func (p *p_Logical) negate() Predicate {
+	values := make([]Predicate, len(p.values))
+	for i, value := range p.values {
+		values[i] = Not(value)
+	}
+	if p.op == "AND" {
+		return Or(values...)
+	} else {
+		return And(values...)
+	}
+}
+
+func (p *p_TagClause) negate() Predicate {
+	if len(p.value) > 1 {
+		values := make([]Predicate, len(p.value))
+		for i, value := range p.value {
+			values[i] = &p_TagClause{name: p.name, value: []string{value}, op: "!="}
+		}
+		return And(values...)
+	}
+	return &p_TagClause{name: p.name, value: p.value, op: negateOp[p.op]}
+}
+
+func (p *p_TagRegex) negate() Predicate {
+	return &p_TagRegex{name: p.name, pattern: p.pattern, op: negateOp[p.op]}
+}
+
+func (p *p_TimeClause) negate() Predicate {
+	return &p_TimeClause{op: negateOp[p.op], offset: p.offset}
+}
+
 func (m Measurement) String() string {
 	if m.Database == "" && m.Policy == "" {
 		return Quote(m.Name)
+	} else if m.Database == "" {
+		return Quote(m.Policy) + "." + Quote(m.Name)
 	} else {
 		return Quote(m.Database) + "." + Quote(m.Policy) + "." + Quote(m.Name)
 	}
@@ -330,6 +1391,20 @@ func (q *q_ShowSeries) String() string {
 	return s
 }
 
+func (q *q_ShowSeriesCardinality) String() string {
+	s := "SHOW SERIES CARDINALITY"
+	if q.exact {
+		s = "SHOW SERIES EXACT CARDINALITY"
+	}
+	if len(q.database) > 0 {
+		s = s + " ON " + Quote(q.database)
+	}
+	if q.measurement != nil {
+		s = s + " FROM " + q.measurement.String()
+	}
+	return s
+}
+
 func (q *q_ShowMeasurements) String() string {
 	s := "SHOW MEASUREMENTS"
 	if len(q.database) > 0 {
@@ -414,7 +1489,24 @@ func (q *q_DropDatabase) String() string {
 }
 
 func (q *q_Select) String() string {
-	s := "SELECT * FROM "
+	s := "SELECT "
+	if len(q.columns) == 0 {
+		s = s + "*"
+	} else {
+		for i, column := range q.columns {
+			s = s + column.String()
+			if (i + 1) < len(q.columns) {
+				s = s + ","
+			}
+		}
+	}
+	if q.into != nil {
+		s = s + " INTO " + q.into.String()
+	}
+	s = s + " FROM "
+	if len(q.from) > 0 {
+		s = s + strings.Join(q.from, ",")
+	}
 	for i, m := range q.measurement {
 		s = s + m.String()
 		if (i + 1) < len(q.measurement) {
@@ -430,11 +1522,158 @@ func (q *q_Select) String() string {
 			}
 		}
 	}
+	group := make([]string, 0, len(q.group)+1)
+	if q.interval > 0 {
+		if q.intervalOffset != 0 {
+			group = append(group, "time("+formatDuration(q.interval)+","+formatDuration(q.intervalOffset)+")")
+		} else {
+			group = append(group, "time("+formatDuration(q.interval)+")")
+		}
+	}
+	for _, tag := range q.group {
+		if tag == "*" {
+			group = append(group, tag)
+		} else {
+			group = append(group, Quote(tag))
+		}
+	}
+	if len(group) > 0 {
+		s = s + " GROUP BY " + strings.Join(group, ",")
+	}
 	if q.limit > 0 {
 		s = s + " LIMIT " + fmt.Sprint(q.limit)
 	}
 	if q.offset > 0 {
 		s = s + " OFFSET " + fmt.Sprint(q.offset)
 	}
+	if q.slimit > 0 {
+		s = s + " SLIMIT " + fmt.Sprint(q.slimit)
+	}
+	if q.soffset > 0 {
+		s = s + " SOFFSET " + fmt.Sprint(q.soffset)
+	}
+	if q.tz != "" {
+		s = s + " tz(" + literal(q.tz) + ")"
+	}
+	return s
+}
+
+func (q *q_ShowQueries) String() string {
+	return "SHOW QUERIES"
+}
+
+func (q *q_KillQuery) String() string {
+	return "KILL QUERY " + fmt.Sprint(q.qid)
+}
+
+func (q *q_ShowShards) String() string {
+	return "SHOW SHARDS"
+}
+
+func (q *q_ShowShardGroups) String() string {
+	return "SHOW SHARD GROUPS"
+}
+
+func (q *q_DropShard) String() string {
+	return "DROP SHARD " + fmt.Sprint(q.id)
+}
+
+func (q *q_CreateContinuousQuery) String() string {
+	return "CREATE CONTINUOUS QUERY " + Quote(q.name) + " ON " + Quote(q.database) + " BEGIN " + q.query.String() + " END"
+}
+
+func (q *q_Raw) String() string {
+	return q.statement
+}
+
+func (q *q_ShowDiagnostics) String() string {
+	return "SHOW DIAGNOSTICS"
+}
+
+func (q *q_ShowStats) String() string {
+	if q.module == "" {
+		return "SHOW STATS"
+	}
+	return "SHOW STATS FOR " + literal(q.module)
+}
+
+func (q *q_ShowFieldKeys) String() string {
+	s := "SHOW FIELD KEYS"
+	if len(q.database) > 0 {
+		s = s + " ON " + Quote(q.database)
+	}
+	if q.measurement != nil {
+		s = s + " FROM " + q.measurement.String()
+	}
+	return s
+}
+
+func (q *q_ShowTagKeys) String() string {
+	s := "SHOW TAG KEYS"
+	if len(q.database) > 0 {
+		s = s + " ON " + Quote(q.database)
+	}
+	if q.measurement != nil {
+		s = s + " FROM " + q.measurement.String()
+	}
+	return s
+}
+
+func (q *q_ShowTagValues) String() string {
+	s := "SHOW TAG VALUES"
+	if len(q.database) > 0 {
+		s = s + " ON " + Quote(q.database)
+	}
+	if q.measurement != nil {
+		s = s + " FROM " + q.measurement.String()
+	}
+	switch len(q.keys) {
+	case 0:
+		s = s + " WITH KEY =~ /.*/"
+	case 1:
+		s = s + " WITH KEY = " + QuoteString(q.keys[0])
+	default:
+		keys := make([]string, len(q.keys))
+		for i, key := range q.keys {
+			keys[i] = QuoteString(key)
+		}
+		s = s + " WITH KEY IN (" + strings.Join(keys, ",") + ")"
+	}
+	return s
+}
+
+func (q *q_DropMeasurement) String() string {
+	return "DROP MEASUREMENT " + Quote(q.name)
+}
+
+func (q *q_DropSeries) String() string {
+	s := "DROP SERIES FROM " + q.measurement.String()
+	if len(q.where) > 0 {
+		where := make([]string, len(q.where))
+		for i, predicate := range q.where {
+			where[i] = predicate.String()
+		}
+		s = s + " WHERE " + strings.Join(where, " AND ")
+	}
+	return s
+}
+
+func (q *q_ShowSubscriptions) String() string {
+	return "SHOW SUBSCRIPTIONS"
+}
+
+func (q *q_ShowUsers) String() string {
+	return "SHOW USERS"
+}
+
+func (q *q_ShowGrants) String() string {
+	return "SHOW GRANTS FOR " + QuoteString(q.user)
+}
+
+func (q *q_CreateUser) String() string {
+	s := "CREATE USER " + QuoteString(q.user) + " WITH PASSWORD " + literal(q.password)
+	if q.admin {
+		s = s + " WITH ALL PRIVILEGES"
+	}
 	return s
 }
@@ -61,6 +61,24 @@ func QuoteString(value string) string {
 	return "\"" + escapeString(value) + "\""
 }
 
+// Unquote reverses Quote and QuoteString, removing surrounding double
+// quotes and unescaping backslash + double quote and double backslashes.
+// Values which are not quoted are returned unchanged
+func Unquote(value string) string {
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return value
+	}
+	value = value[1 : len(value)-1]
+	unquoted := make([]byte, 0, len(value))
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+1 < len(value) {
+			i++
+		}
+		unquoted = append(unquoted, value[i])
+	}
+	return string(unquoted)
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // PRIVATE METHODS
 
@@ -81,6 +99,39 @@ func escapeString(value string) string {
 	return value
 }
 
+// escapeMeasurement escapes commas and spaces in a line protocol
+// measurement name
+func escapeMeasurement(value string) string {
+	value = strings.Replace(value, ",", "\\,", -1)
+	value = strings.Replace(value, " ", "\\ ", -1)
+	return value
+}
+
+// escapeTag escapes commas, equals signs and spaces in a line protocol
+// tag key, tag value or field key
+func escapeTag(value string) string {
+	value = strings.Replace(value, ",", "\\,", -1)
+	value = strings.Replace(value, "=", "\\=", -1)
+	value = strings.Replace(value, " ", "\\ ", -1)
+	return value
+}
+
+// escapeRegex escapes slashes in a regular expression which are not
+// already escaped, so the expression can be written between slashes
+func escapeRegex(value string) string {
+	var result strings.Builder
+	escaped := false
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c == '/' && escaped == false {
+			result.WriteByte('\\')
+		}
+		escaped = c == '\\' && escaped == false
+		result.WriteByte(c)
+	}
+	return result.String()
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // RESERVED WORDS HASH
 
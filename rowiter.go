@@ -0,0 +1,165 @@
+/*
+	InfluxDB client
+	(c) Copyright David Thorpe 2017
+	All Rights Reserved
+
+	For Licensing and Usage information, please see LICENSE file
+*/
+
+package influxdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// RowIter iterates over the rows of a set of results, in the manner of
+// database/sql rows. Values are converted to the types of the destinations
+// passed to Scan as each row is scanned
+type RowIter struct {
+	results Results
+	result  int
+	row     int
+	err     error
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTRUCTOR
+
+// NewRowIter returns an iterator over the rows of a set of results. The
+// error is returned by Err once the rows have been read, for example
+// ErrPartialResult when the results are incomplete
+func NewRowIter(results Results, err error) *RowIter {
+	return &RowIter{results: results, row: -1, err: err}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Next advances to the next row, returning false when there are no
+// more rows
+func (r *RowIter) Next() bool {
+	for r.result < len(r.results) {
+		if r.row++; r.row < len(r.results[r.result].Values) {
+			return true
+		}
+		r.result, r.row = r.result+1, -1
+	}
+	return false
+}
+
+// Result returns the series for the current row, which contains the
+// columns, name and tags of the row
+func (r *RowIter) Result() *Result {
+	if r.result < len(r.results) {
+		return r.results[r.result]
+	}
+	return nil
+}
+
+// Columns returns the column names for the current row
+func (r *RowIter) Columns() []string {
+	if result := r.Result(); result != nil {
+		return result.Columns
+	}
+	return nil
+}
+
+// Scan copies the values of the current row into the destinations, which
+// must be one for each column. Destinations may be pointers to string,
+// bool, int, int64, uint64, float64, time.Time or interface{}. Null values
+// can only be scanned into an interface{}, and return an error wrapping
+// ErrBadParameter otherwise
+func (r *RowIter) Scan(dest ...interface{}) error {
+	result := r.Result()
+	if result == nil || r.row < 0 || r.row >= len(result.Values) {
+		return fmt.Errorf("%w: Scan called without a row", ErrBadParameter)
+	}
+	row := result.Values[r.row]
+	if len(dest) != len(row) {
+		return fmt.Errorf("%w: expected %v destinations, got %v", ErrBadParameter, len(row), len(dest))
+	}
+	for i, value := range row {
+		column := ""
+		if i < len(result.Columns) {
+			column = result.Columns[i]
+		}
+		if err := scanValue(dest[i], value); err != nil {
+			return fmt.Errorf("column %q: %w", column, err)
+		}
+	}
+	return nil
+}
+
+// Err returns the error from the query once the rows have been read
+func (r *RowIter) Err() error {
+	return r.err
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// scanValue converts a server response value to the type of dest
+func scanValue(dest, value interface{}) error {
+	if dest, ok := dest.(*interface{}); ok {
+		*dest = value
+		return nil
+	} else if value == nil {
+		return fmt.Errorf("%w: null value", ErrBadParameter)
+	}
+	var err error
+	switch dest := dest.(type) {
+	case *string:
+		switch value := value.(type) {
+		case string:
+			*dest = value
+		case json.Number:
+			*dest = value.String()
+		default:
+			*dest = fmt.Sprint(value)
+		}
+		return nil
+	case *bool:
+		if b, ok := toBool(value); ok {
+			*dest = b
+			return nil
+		}
+	case *int64:
+		if n, ok := value.(json.Number); ok {
+			if *dest, err = strconv.ParseInt(n.String(), 10, 64); err == nil {
+				return nil
+			}
+		}
+	case *int:
+		if n, ok := value.(json.Number); ok {
+			if i, err := strconv.ParseInt(n.String(), 10, 0); err == nil {
+				*dest = int(i)
+				return nil
+			}
+		}
+	case *uint64:
+		if n, ok := toUint(value); ok {
+			*dest = n
+			return nil
+		}
+	case *float64:
+		if n, ok := value.(json.Number); ok {
+			if *dest, err = n.Float64(); err == nil {
+				return nil
+			}
+		}
+	case *time.Time:
+		if t, ok := toTime(value); ok {
+			*dest = t
+			return nil
+		}
+	default:
+		return fmt.Errorf("%w: unsupported destination %T", ErrBadParameter, dest)
+	}
+	return fmt.Errorf("%w: cannot convert %v to %T", ErrBadParameter, value, dest)
+}
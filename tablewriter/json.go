@@ -0,0 +1,33 @@
+package tablewriter
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/djthorpe/influxdb"
+)
+
+type jsonResult struct {
+	Name    string            `json:"name,omitempty"`
+	Tags    map[string]string `json:"tags,omitempty"`
+	Columns []string          `json:"columns"`
+	Values  [][]interface{}   `json:"values"`
+}
+
+// RenderJSON writes the result as a JSON object followed by a newline. When
+// pretty is true, the output is indented with two spaces
+func RenderJSON(result *influxdb.Result, writer io.Writer, pretty bool) error {
+	value := jsonResult{result.Name, result.Tags, result.Columns, result.Values}
+	var data []byte
+	var err error
+	if pretty {
+		data, err = json.MarshalIndent(value, "", "  ")
+	} else {
+		data, err = json.Marshal(value)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(append(data, '\n'))
+	return err
+}
@@ -0,0 +1,40 @@
+package tablewriter
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/djthorpe/influxdb"
+)
+
+func TestRenderJSON_001(t *testing.T) {
+	result := &influxdb.Result{
+		Name:    "cpu",
+		Tags:    map[string]string{"host": "a"},
+		Columns: []string{"time", "value"},
+		Values:  [][]interface{}{{"2018-01-01T00:00:00Z", 1.5}, {"2018-01-01T00:01:00Z", 2.5}},
+	}
+	compact, pretty := new(bytes.Buffer), new(bytes.Buffer)
+	if err := RenderJSON(result, compact, false); err != nil {
+		t.Error(err)
+	} else if err := RenderJSON(result, pretty, true); err != nil {
+		t.Error(err)
+	} else if pretty.Len() <= compact.Len() {
+		t.Errorf("Expected pretty output (%v bytes) to be longer than compact (%v bytes)", pretty.Len(), compact.Len())
+	} else if bytes.Contains(compact.Bytes(), []byte("\n  ")) {
+		t.Error("Unexpected indentation in compact output")
+	} else if !bytes.Contains(pretty.Bytes(), []byte("\n  \"name\"")) {
+		t.Errorf("Expected two-space indentation: %v", pretty.String())
+	} else {
+		var a, b interface{}
+		if err := json.Unmarshal(compact.Bytes(), &a); err != nil {
+			t.Error(err)
+		} else if err := json.Unmarshal(pretty.Bytes(), &b); err != nil {
+			t.Error(err)
+		} else if !reflect.DeepEqual(a, b) {
+			t.Errorf("Expected equivalent output: %v and %v", a, b)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package tablewriter
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/djthorpe/influxdb"
+)
+
+// Renderer renders results with options for formatting time columns,
+// which are detected from their values. When neither TimeFormat nor
+// TimeZone is set, values are rendered as returned by the server
+type Renderer struct {
+	// TimeFormat is the Go layout for times, or RFC3339Nano when empty
+	TimeFormat string
+
+	// TimeZone is the location for times, or UTC when nil
+	TimeZone *time.Location
+
+	// EpochUnit is the unit of numeric timestamps, as set by the query
+	// precision. When zero, numeric timestamps are not formatted
+	EpochUnit time.Duration
+}
+
+// RenderASCII writes the result as an ASCII table
+func (r Renderer) RenderASCII(result *influxdb.Result, writer io.Writer) error {
+	return RenderASCII(r.format(result), writer)
+}
+
+// RenderJSON writes the result as a JSON object
+func (r Renderer) RenderJSON(result *influxdb.Result, writer io.Writer, pretty bool) error {
+	return RenderJSON(r.format(result), writer, pretty)
+}
+
+// format returns a copy of the result with time columns formatted
+func (r Renderer) format(result *influxdb.Result) *influxdb.Result {
+	if r.TimeFormat == "" && r.TimeZone == nil {
+		return result
+	}
+	columns := make([]bool, len(result.Columns))
+	for i, column := range result.InferColumnTypes() {
+		columns[i] = column == "time" || (result.Columns[i] == "time" && r.EpochUnit > 0)
+	}
+	values := make([][]interface{}, len(result.Values))
+	for i, row := range result.Values {
+		values[i] = make([]interface{}, len(row))
+		for j, value := range row {
+			if j < len(columns) && columns[j] {
+				values[i][j] = r.formatTime(value)
+			} else {
+				values[i][j] = value
+			}
+		}
+	}
+	return &influxdb.Result{
+		Result:  result.Result,
+		Series:  result.Series,
+		Name:    result.Name,
+		Tags:    result.Tags,
+		Columns: result.Columns,
+		Values:  values,
+		Partial: result.Partial,
+	}
+}
+
+// formatTime returns a time value formatted, or the value unchanged if
+// it is not a time
+func (r Renderer) formatTime(value interface{}) interface{} {
+	var t time.Time
+	switch value := value.(type) {
+	case time.Time:
+		t = value
+	case string:
+		if ts, err := time.Parse(time.RFC3339Nano, value); err != nil {
+			return value
+		} else {
+			t = ts
+		}
+	case json.Number:
+		if n, err := strconv.ParseInt(value.String(), 10, 64); err != nil || r.EpochUnit <= 0 {
+			return value
+		} else {
+			t = time.Unix(0, n*int64(r.EpochUnit))
+		}
+	default:
+		return value
+	}
+	location, layout := r.TimeZone, r.TimeFormat
+	if location == nil {
+		location = time.UTC
+	}
+	if layout == "" {
+		layout = time.RFC3339Nano
+	}
+	return t.In(location).Format(layout)
+}
@@ -0,0 +1,45 @@
+package tablewriter
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/djthorpe/influxdb"
+)
+
+func TestRenderer_001(t *testing.T) {
+	zone := time.FixedZone("CET", 3600)
+	result := &influxdb.Result{
+		Name:    "cpu",
+		Columns: []string{"time", "host", "value"},
+		Values: [][]interface{}{
+			{"2018-01-01T00:00:00Z", "a", json.Number("1")},
+			{"2018-01-01T23:30:00Z", "b", json.Number("2")},
+		},
+	}
+	buf := new(bytes.Buffer)
+	renderer := Renderer{TimeFormat: "2006-01-02 15:04 MST", TimeZone: zone}
+	if err := renderer.RenderJSON(result, buf, false); err != nil {
+		t.Error(err)
+	} else if out := buf.String(); strings.Contains(out, `"2018-01-01 01:00 CET"`) == false || strings.Contains(out, `"2018-01-02 00:30 CET"`) == false {
+		t.Error("Expected times in CET, got", out)
+	} else if result.Values[0][0] != "2018-01-01T00:00:00Z" {
+		t.Error("Expected result to be unchanged, got", result.Values[0][0])
+	}
+
+	// Numeric timestamps are formatted when the unit is known
+	result = &influxdb.Result{
+		Columns: []string{"time", "value"},
+		Values:  [][]interface{}{{json.Number("1514764800000"), json.Number("1")}},
+	}
+	buf.Reset()
+	renderer = Renderer{TimeZone: zone, EpochUnit: time.Millisecond}
+	if err := renderer.RenderJSON(result, buf, false); err != nil {
+		t.Error(err)
+	} else if out := buf.String(); strings.Contains(out, `"2018-01-01T01:00:00+01:00"`) == false {
+		t.Error("Expected epoch time in CET, got", out)
+	}
+}
@@ -0,0 +1,109 @@
+/*
+	InfluxDB client
+	(c) Copyright David Thorpe 2017
+	All Rights Reserved
+
+	For Licensing and Usage information, please see LICENSE file
+*/
+
+package v2
+
+import (
+	"encoding/json"
+	"time"
+
+	influxdb "github.com/djthorpe/influxdb"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// AGGREGATES
+
+// Mean returns the mean of a field over a period up to now
+func (this *Client) Mean(measurement, field string, since time.Duration) (float64, error) {
+	return this.aggregate(measurement, influxdb.Mean(field), since)
+}
+
+// Max returns the maximum value of a field over a period up to now
+func (this *Client) Max(measurement, field string, since time.Duration) (float64, error) {
+	return this.aggregate(measurement, influxdb.Max(field), since)
+}
+
+// Min returns the minimum value of a field over a period up to now
+func (this *Client) Min(measurement, field string, since time.Duration) (float64, error) {
+	return this.aggregate(measurement, influxdb.Min(field), since)
+}
+
+// Sum returns the sum of a field over a period up to now
+func (this *Client) Sum(measurement, field string, since time.Duration) (float64, error) {
+	return this.aggregate(measurement, influxdb.Sum(field), since)
+}
+
+// Count returns the number of values of a field over a period up to now
+func (this *Client) Count(measurement, field string, since time.Duration) (float64, error) {
+	return this.aggregate(measurement, influxdb.Count(field), since)
+}
+
+// Percentile returns the pth percentile value of a field over a period up
+// to now, where p is between 0 and 100
+func (this *Client) Percentile(measurement, field string, p float64, since time.Duration) (float64, error) {
+	if p < 0 || p > 100 {
+		return 0, influxdb.ErrBadParameter
+	}
+	return this.aggregate(measurement, influxdb.Percentile(field, p), since)
+}
+
+// LastValue returns the most recent value of a field and its timestamp,
+// or ErrEmptyResponse if the measurement contains no values for the field
+func (this *Client) LastValue(measurement, field string) (time.Time, influxdb.Value, error) {
+	if this.client == nil {
+		return time.Time{}, nil, influxdb.ErrNotConnected
+	}
+	if measurement == "" || field == "" {
+		return time.Time{}, nil, influxdb.ErrBadParameter
+	}
+	q := influxdb.Select(&influxdb.Measurement{Name: measurement}).Columns(influxdb.Last(field))
+	if results, err := this.Do(q); err != nil {
+		return time.Time{}, nil, err
+	} else if times, err := results.Column(0, measurement, "time"); err != nil {
+		return time.Time{}, nil, influxdb.ErrUnexpectedResponse
+	} else if values, err := results.Column(0, measurement, "last"); err != nil {
+		return time.Time{}, nil, influxdb.ErrUnexpectedResponse
+	} else if len(times) != 1 || len(values) != 1 {
+		return time.Time{}, nil, influxdb.ErrUnexpectedResponse
+	} else if ts, ok := times[0].(time.Time); ok == false {
+		return time.Time{}, nil, influxdb.ErrUnexpectedResponse
+	} else {
+		return ts, values[0], nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// aggregate runs a query selecting a single function over a period up to
+// now, and returns the scalar value, or ErrEmptyResponse if there
+// is no data in the period
+func (this *Client) aggregate(measurement string, function influxdb.Predicate, since time.Duration) (float64, error) {
+	if this.client == nil {
+		return 0, influxdb.ErrNotConnected
+	}
+	if measurement == "" || since <= 0 {
+		return 0, influxdb.ErrBadParameter
+	}
+	where, err := influxdb.WhereSince(since)
+	if err != nil {
+		return 0, err
+	}
+	q := influxdb.Select(&influxdb.Measurement{Name: measurement}).Columns(function).Filter(where)
+	if results, err := this.Do(q); err != nil {
+		return 0, err
+	} else if len(results) != 1 || len(results[0].Values) != 1 || len(results[0].Values[0]) != 2 {
+		return 0, influxdb.ErrUnexpectedResponse
+	} else if results[0].Values[0][1] == nil {
+		return 0, influxdb.ErrEmptyResponse
+	} else if value, ok := results[0].Values[0][1].(json.Number); ok == false {
+		return 0, influxdb.ErrUnexpectedResponse
+	} else {
+		return value.Float64()
+	}
+}
@@ -0,0 +1,211 @@
+/*
+	InfluxDB client
+	(c) Copyright David Thorpe 2017
+	All Rights Reserved
+
+	For Licensing and Usage information, please see LICENSE file
+*/
+
+package v2
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+
+	influxdb "github.com/djthorpe/influxdb"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// queryCache holds the results of queries for a period of time, keyed by
+// database and query string
+type queryCache struct {
+	sync.Mutex
+	ttl     time.Duration
+	entries map[string]*cacheEntry
+}
+
+// cacheEntry is a cached set of results
+type cacheEntry struct {
+	database string
+	query    string
+	results  influxdb.Results
+	expires  time.Time
+}
+
+// schemaCache holds the field types of recently used measurements for a
+// period of time, discarding the least recently used measurement when full
+type schemaCache struct {
+	sync.Mutex
+	ttl     time.Duration
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+// schemaEntry is the cached field types of a measurement
+type schemaEntry struct {
+	key     string
+	fields  map[string]string
+	expires time.Time
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTRUCTOR
+
+// newQueryCache returns an empty cache which holds results for ttl
+func newQueryCache(ttl time.Duration) *queryCache {
+	return &queryCache{
+		ttl:     ttl,
+		entries: make(map[string]*cacheEntry),
+	}
+}
+
+// newSchemaCache returns an empty cache which holds the field types of up
+// to size measurements for ttl
+func newSchemaCache(ttl time.Duration, size int) *schemaCache {
+	return &schemaCache{
+		ttl:     ttl,
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// WITH CACHE
+
+// WithCache returns a copy of the client which caches the results of
+// SELECT and SHOW queries made with Do for the ttl, so that repeating a
+// query within the ttl does not contact the server. Cached results are
+// shared between callers and must not be modified. When
+// CacheInvalidateOnWrite is set, writes through the returned client remove
+// cached queries which name a measurement written to. A ttl of zero or
+// less returns a copy without a cache
+func (this *Client) WithCache(ttl time.Duration) influxdb.Client {
+	other := *this
+	if ttl > 0 {
+		other.cache = newQueryCache(ttl)
+	} else {
+		other.cache = nil
+	}
+	return &other
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// cacheable returns true if a query only reads data, so that its results
+// can be cached
+func cacheable(query string) bool {
+	query = strings.ToUpper(strings.TrimSpace(query))
+	if strings.HasPrefix(query, "SHOW ") {
+		return true
+	} else if strings.HasPrefix(query, "SELECT ") {
+		return strings.Contains(query, " INTO ") == false
+	} else {
+		return false
+	}
+}
+
+// get returns the cached results for a query, or nil if the query is not
+// cached or has expired
+func (this *queryCache) get(database, query string) influxdb.Results {
+	this.Lock()
+	defer this.Unlock()
+	key := database + "\x00" + query
+	if entry, exists := this.entries[key]; exists == false {
+		return nil
+	} else if time.Now().After(entry.expires) {
+		delete(this.entries, key)
+		return nil
+	} else {
+		return append(influxdb.Results(nil), entry.results...)
+	}
+}
+
+// set caches the results for a query, and removes any expired entries
+func (this *queryCache) set(database, query string, results influxdb.Results) {
+	this.Lock()
+	defer this.Unlock()
+	now := time.Now()
+	for key, entry := range this.entries {
+		if now.After(entry.expires) {
+			delete(this.entries, key)
+		}
+	}
+	this.entries[database+"\x00"+query] = &cacheEntry{
+		database: database,
+		query:    query,
+		results:  results,
+		expires:  now.Add(this.ttl),
+	}
+}
+
+// invalidate removes cached queries for a database which name a
+// measurement. Queries are matched on the quoted measurement name, so
+// other queries which contain the name are also removed
+func (this *queryCache) invalidate(database, measurement string) {
+	this.Lock()
+	defer this.Unlock()
+	name := influxdb.Quote(measurement)
+	for key, entry := range this.entries {
+		if entry.database == database && strings.Contains(entry.query, name) {
+			delete(this.entries, key)
+		}
+	}
+}
+
+// get returns the cached field types for a measurement, or nil if the
+// measurement is not cached or has expired
+func (this *schemaCache) get(database, measurement string) map[string]string {
+	this.Lock()
+	defer this.Unlock()
+	if element, exists := this.entries[database+"\x00"+measurement]; exists == false {
+		return nil
+	} else if entry := element.Value.(*schemaEntry); time.Now().After(entry.expires) {
+		this.order.Remove(element)
+		delete(this.entries, entry.key)
+		return nil
+	} else {
+		this.order.MoveToFront(element)
+		return entry.fields
+	}
+}
+
+// set caches the field types for a measurement, and removes the least
+// recently used measurement when the cache is full
+func (this *schemaCache) set(database, measurement string, fields map[string]string) {
+	this.Lock()
+	defer this.Unlock()
+	key := database + "\x00" + measurement
+	entry := &schemaEntry{key: key, fields: fields, expires: time.Now().Add(this.ttl)}
+	if element, exists := this.entries[key]; exists {
+		element.Value = entry
+		this.order.MoveToFront(element)
+		return
+	}
+	this.entries[key] = this.order.PushFront(entry)
+	for this.order.Len() > this.size {
+		oldest := this.order.Back()
+		this.order.Remove(oldest)
+		delete(this.entries, oldest.Value.(*schemaEntry).key)
+	}
+}
+
+// invalidate removes the cached field types for a measurement, or for
+// all measurements when the measurement is empty
+func (this *schemaCache) invalidate(database, measurement string) {
+	this.Lock()
+	defer this.Unlock()
+	if measurement == "" {
+		this.order.Init()
+		this.entries = make(map[string]*list.Element)
+	} else if element, exists := this.entries[database+"\x00"+measurement]; exists {
+		this.order.Remove(element)
+		delete(this.entries, database+"\x00"+measurement)
+	}
+}
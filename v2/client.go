@@ -9,7 +9,14 @@
 package v2
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	gopi "github.com/djthorpe/gopi"
@@ -31,29 +38,182 @@ type Config struct {
 	Password  string
 	Precision string
 	Timeout   time.Duration
+	DryRun    bool
+
+	// WriteRateLimit is the maximum number of points written per second,
+	// or zero for no limit. Writes above the limit block until allowed
+	WriteRateLimit int
+
+	// WriteRetentionPolicy is the retention policy for writes of points
+	// with an unqualified measurement name, or empty for the default
+	WriteRetentionPolicy string
+
+	// SkipPing when true does not contact the server on Open, so that a
+	// client can be created before the server is available. Connection
+	// errors are returned by the first operation instead
+	SkipPing bool
+
+	// WriteConsistency is the write consistency level for clusters, which is
+	// one of "any", "one", "quorum" or "all", or empty for the server default
+	WriteConsistency string
+
+	// Headers are added to every request, for proxies and gateways which
+	// route on headers. Headers set by the client, such as Authorization
+	// for the username and password, take precedence
+	Headers map[string]string
+
+	// CacheInvalidateOnWrite when true removes cached query results for
+	// measurements which are written to, for clients returned by WithCache
+	CacheInvalidateOnWrite bool
+
+	// SchemaCacheTTL when non-zero is the time for which the field types
+	// of a measurement are cached by ValidatePoint, for the most recently
+	// used measurements. RefreshSchema removes cached field types
+	SchemaCacheTTL time.Duration
+
+	// WriteBatchSize is the number of points in each write made by
+	// WriteJSONStream, or zero for the default of 5000
+	WriteBatchSize int
+
+	// KeepAlive is the interval between TCP keep-alive probes and
+	// IdleConnTimeout is the time an idle connection is kept open before
+	// it is closed, or zero for the defaults of 30 and 90 seconds, so
+	// that connections are not killed by proxies and load balancers
+	KeepAlive       time.Duration
+	IdleConnTimeout time.Duration
+}
+
+// serverVersion is the version reported by the server, which is shared
+// between copies of a client
+type serverVersion struct {
+	sync.RWMutex
+	value string
 }
 
 // Client defines a connection to an Influx Database
 type Client struct {
-	log       gopi.Logger
-	database  string
-	addr      string
-	config    client.HTTPConfig
-	precision string
-	client    client.Client
-	version   string
+	log         gopi.Logger
+	database    string
+	addr        string
+	config      client.HTTPConfig
+	precision   string
+	client      client.Client
+	http        *http.Client
+	version     *serverVersion
+	metrics     *metrics
+	dryrun      bool
+	limiter     *tokenBucket
+	policy      string
+	consistency string
+	headers     map[string]string
+	settings    Config
+	cache       *queryCache
+	schema      *schemaCache
+	transport   *http.Transport
 }
 
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	// killTimeout is the time allowed for killing a query on the server
+	// after a timeout
+	killTimeout = 5 * time.Second
+
+	// throttleRetries is the number of times a request is retried when
+	// the server responds with 429 Too Many Requests and Retry-After
+	throttleRetries = 3
+
+	// throttleMaxDelay is the longest Retry-After delay which is waited
+	// for before retrying, so that a request without a deadline is not
+	// blocked for hours. Longer delays return the 429 response
+	throttleMaxDelay = 30 * time.Second
+
+	// downsampleRawDuration is the duration of the retention policy for
+	// raw data created by SetupDownsampling
+	downsampleRawDuration = 7 * 24 * time.Hour
+
+	// renameBatchSize is the number of points written in each batch when
+	// renaming a tag
+	renameBatchSize = 5000
+
+	// defaultWriteBatchSize is the number of points in each write made by
+	// WriteJSONStream when WriteBatchSize is not set
+	defaultWriteBatchSize = 5000
+
+	// defaultKeepAlive is the interval between TCP keep-alive probes
+	// when KeepAlive is not set
+	defaultKeepAlive = 30 * time.Second
+
+	// defaultIdleConnTimeout is the time an idle connection is kept open
+	// when IdleConnTimeout is not set
+	defaultIdleConnTimeout = 90 * time.Second
+
+	// schemaCacheSize is the number of measurements for which field types
+	// are cached when SchemaCacheTTL is set
+	schemaCacheSize = 100
+
+	// verifyInterval is the time between attempts to read back a point
+	// written with WriteVerified
+	verifyInterval = 50 * time.Millisecond
+
+	// redacted replaces credentials in the configuration returned by Config
+	redacted = "********"
+)
+
+var (
+	// redactedHeaders are headers which contain credentials
+	redactedHeaders = []string{"Authorization", "Proxy-Authorization"}
+
+	// regexpPassword matches a password literal in a statement, so that
+	// it can be redacted when the statement is logged
+	regexpPassword = regexp.MustCompile("(?i)(PASSWORD\\s+)'(?:[^'\\\\]|\\\\.)*'")
+
+	// regexpVersion matches the major and minor parts of a version string
+	regexpVersion = regexp.MustCompile("^v?(\\d+)\\.(\\d+)")
+
+	// writeConsistency is the set of valid write consistency levels
+	writeConsistency = map[string]bool{
+		"any": true, "one": true, "quorum": true, "all": true,
+	}
+)
+
 ////////////////////////////////////////////////////////////////////////////////
 // OPEN AND CLOSE
 
 // Open returns an InfluxDB client object
 func (config Config) Open(log gopi.Logger) (gopi.Driver, error) {
+	if this, err := config.open(log, nil); err != nil {
+		return nil, err
+	} else {
+		return this, nil
+	}
+}
+
+// open returns a client which uses the transport for HTTP requests, or
+// a new transport for the configuration when the transport is nil
+func (config Config) open(log gopi.Logger, transport *http.Transport) (*Client, error) {
 	log.Debug2("<influxdb.Client>Open{ addr=%v database=%v }", config.addr(), config.Database)
 
+	// Check configuration
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
 	this := new(Client)
 	this.log = log
 	this.addr = config.addr()
+	this.dryrun = config.DryRun
+	this.policy = config.WriteRetentionPolicy
+	this.consistency = config.WriteConsistency
+	this.headers = config.Headers
+	this.settings = config
+	if config.WriteRateLimit > 0 {
+		this.limiter = newTokenBucket(config.WriteRateLimit)
+	}
+	if config.SchemaCacheTTL > 0 {
+		this.schema = newSchemaCache(config.SchemaCacheTTL, schemaCacheSize)
+	}
 	this.config = client.HTTPConfig{
 		Addr:               this.addr,
 		Username:           config.Username,
@@ -66,18 +226,31 @@ func (config Config) Open(log gopi.Logger) (gopi.Driver, error) {
 	if this.client, err = client.NewHTTPClient(this.config); err != nil {
 		return nil, this.log.Error("%v", err)
 	}
+	if transport == nil {
+		transport = newTransport(config)
+	}
+	this.transport = transport
+	this.http = newHTTPClient(this.config, this.headers, transport)
 
-	// Ping client to make sure it exists, get InfluxDB version
-	var t time.Duration
-	if t, this.version, err = this.client.Ping(this.config.Timeout); err != nil {
-		this.client.Close()
-		this.client = nil
-		return nil, this.log.Error("%v", err)
+	// Ping client to make sure it exists, get InfluxDB version. When the
+	// ping is skipped the version is set after the first successful query
+	this.version = new(serverVersion)
+	this.metrics = new(metrics)
+	if config.SkipPing == false {
+		if t, version, err := this.pingVersion(); err != nil {
+			this.client.Close()
+			this.client = nil
+			return nil, this.log.Error("%v", err)
+		} else {
+			this.version.set(version)
+			this.log.Debug("InfluxDB Version=%v Ping=%v", version, t)
+		}
 	}
-	this.log.Debug("InfluxDB Version=%v Ping=%v", this.version, t)
 
-	// Set database
-	if config.Database != "" {
+	// Set database, which is not checked when the ping is skipped
+	if config.Database != "" && config.SkipPing {
+		this.database = config.Database
+	} else if config.Database != "" {
 		if err := this.SetDatabase(config.Database); err != nil {
 			return nil, this.log.Error("Unknown database: %v", config.Database)
 		}
@@ -110,6 +283,45 @@ func (this *Client) Close() error {
 	return nil
 }
 
+// Validate returns ErrBadParameter with the reasons the configuration is
+// invalid, or nil when it is valid. Open calls Validate before connecting
+func (config Config) Validate() error {
+	var result error
+	if strings.TrimSpace(config.Host) == "" {
+		result = errors.Join(result, fmt.Errorf("%w: empty host", influxdb.ErrBadParameter))
+	}
+	if config.Password != "" && config.Username == "" {
+		result = errors.Join(result, fmt.Errorf("%w: password without username", influxdb.ErrBadParameter))
+	}
+	switch config.Precision {
+	case "",
+		influxdb.PRECISION_NANO, influxdb.PRECISION_MICRO, influxdb.PRECISION_MICRO2, influxdb.PRECISION_MILLI,
+		influxdb.PRECISION_SECOND, influxdb.PRECISION_MINUTE, influxdb.PRECISION_HOUR,
+		influxdb.PRECISION_DAY, influxdb.PRECISION_WEEK:
+	default:
+		result = errors.Join(result, fmt.Errorf("%w: invalid precision %q", influxdb.ErrBadParameter, config.Precision))
+	}
+	if config.WriteConsistency != "" && writeConsistency[config.WriteConsistency] == false {
+		result = errors.Join(result, fmt.Errorf("%w: invalid write consistency %q", influxdb.ErrBadParameter, config.WriteConsistency))
+	}
+	if config.Timeout < 0 {
+		result = errors.Join(result, fmt.Errorf("%w: negative timeout %v", influxdb.ErrBadParameter, config.Timeout))
+	}
+	if config.WriteRateLimit < 0 {
+		result = errors.Join(result, fmt.Errorf("%w: negative write rate limit %v", influxdb.ErrBadParameter, config.WriteRateLimit))
+	}
+	if config.WriteBatchSize < 0 {
+		result = errors.Join(result, fmt.Errorf("%w: negative write batch size %v", influxdb.ErrBadParameter, config.WriteBatchSize))
+	}
+	if config.KeepAlive < 0 {
+		result = errors.Join(result, fmt.Errorf("%w: negative keep-alive %v", influxdb.ErrBadParameter, config.KeepAlive))
+	}
+	if config.IdleConnTimeout < 0 {
+		result = errors.Join(result, fmt.Errorf("%w: negative idle connection timeout %v", influxdb.ErrBadParameter, config.IdleConnTimeout))
+	}
+	return result
+}
+
 func (config Config) addr() string {
 	method := "http"
 	if config.SSL {
@@ -124,15 +336,72 @@ func (config Config) addr() string {
 ////////////////////////////////////////////////////////////////////////////////
 // PARAMETERS
 
+// Addr returns the URL of the server
+func (this *Client) Addr() string {
+	return this.addr
+}
+
+// Config returns a copy of the configuration used to open the client,
+// with the password and any credentials in the headers masked, so that it
+// can be logged or displayed
+func (this *Client) Config() Config {
+	config := this.settings
+	if config.Password != "" {
+		config.Password = redacted
+	}
+	if config.Headers != nil {
+		config.Headers = make(map[string]string, len(this.settings.Headers))
+		for key, value := range this.settings.Headers {
+			for _, header := range redactedHeaders {
+				if strings.EqualFold(key, header) {
+					value = redacted
+				}
+			}
+			config.Headers[key] = value
+		}
+	}
+	return config
+}
+
 // Version returns the version string for the InfluxDB
 func (this *Client) Version() string {
 	if this.client == nil {
 		return ""
 	} else {
-		return this.version
+		return this.version.get()
 	}
 }
 
+// VersionAtLeast returns true if the InfluxDB version is at least
+// major.minor. Returns false if the version string cannot be parsed
+func (this *Client) VersionAtLeast(major, minor int) bool {
+	if v_major, v_minor, ok := parseVersion(this.Version()); ok == false {
+		return false
+	} else if v_major != major {
+		return v_major > major
+	} else {
+		return v_minor >= minor
+	}
+}
+
+// BuildInfo returns the version, branch, commit and build time of the
+// server from the "build" section of the diagnostics
+func (this *Client) BuildInfo() (*influxdb.BuildInfo, error) {
+	if this.client == nil {
+		return nil, influxdb.ErrNotConnected
+	}
+	results, err := this.Do(influxdb.ShowDiagnostics())
+	if err != nil {
+		return nil, err
+	}
+	for _, result := range results {
+		if result.Name == "build" {
+			return result.ParseBuildInfo()
+		}
+	}
+	return nil, fmt.Errorf("%w: no build diagnostics", influxdb.ErrUnexpectedResponse)
+}
+
 // Precision returns the current precision value
 func (this *Client) Precision() string {
 	if this.client == nil {
@@ -158,6 +427,30 @@ func (this *Client) SetPrecision(value string) error {
 	return nil
 }
 
+// DryRun returns true if destructive operations are not executed
+func (this *Client) DryRun() bool {
+	return this.dryrun
+}
+
+// SetDryRun overrides the configured dry run value. When true, destructive
+// operations log the statement and return a DryRunError containing it,
+// rather than executing it. SetDryRun changes the value for all callers
+// and should not be called while other calls are in progress; use
+// WithDryRun to override the value for individual calls
+func (this *Client) SetDryRun(value bool) {
+	this.dryrun = value
+}
+
+// WithDryRun returns a client which shares the connection with this one
+// but has its own dry run value, for example to execute a single
+// destructive operation with DryRun(false). The returned client has its
+// own current database and precision, and should not be closed
+func (this *Client) WithDryRun(value bool) influxdb.Client {
+	other := *this
+	other.dryrun = value
+	return &other
+}
+
 // Database returns the current database string
 func (this *Client) Database() string {
 	if this.client == nil {
@@ -168,13 +461,15 @@ func (this *Client) Database() string {
 }
 
 // SetDatabase sets the current database to use, will
-// return ErrBadParameter if the database doesn't exist,
+// return ErrDatabaseNotFound if the database doesn't exist,
 // or ErrNotConnected if the server is not connected
 func (this *Client) SetDatabase(name string) error {
 	if this.client == nil {
 		return influxdb.ErrNotConnected
 	}
-	if results, err := this.Do(influxdb.ShowDatabases()); err != nil {
+	if results, err := this.Do(influxdb.ShowDatabases()); err == influxdb.ErrEmptyResponse {
+		return influxdb.ErrDatabaseNotFound
+	} else if err != nil {
 		return err
 	} else if databases, err := results.Column(0, "databases", "name"); err != nil {
 		return err
@@ -186,7 +481,7 @@ func (this *Client) SetDatabase(name string) error {
 			}
 		}
 	}
-	return influxdb.ErrBadParameter
+	return influxdb.ErrDatabaseNotFound
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -214,10 +509,7 @@ func (this *Client) DropDatabase(name string) error {
 		return influxdb.ErrNotConnected
 	}
 	// Perform the drop
-	if _, err := this.Do(influxdb.DropDatabase(name)); err != nil && err != influxdb.ErrEmptyResponse {
-		return err
-	}
-	return nil
+	return this.destroy(influxdb.DropDatabase(name))
 }
 
 func (this *Client) CreateRetentionPolicy(name string, policy *influxdb.RetentionPolicy) error {
@@ -243,8 +535,33 @@ func (this *Client) DropRetentionPolicy(name string) error {
 		return influxdb.ErrNotConnected
 	}
 	// Perform the drop
-	if _, err := this.Do(influxdb.DropRetentionPolicy(this.database, name)); err != nil && err != influxdb.ErrEmptyResponse {
-		return err
+	return this.destroy(influxdb.DropRetentionPolicy(this.database, name))
+}
+
+// SetupDownsampling creates a database with a default retention policy for
+// raw data, which keeps data for seven days, and a retention policy for
+// aggregated data, which keeps data indefinitely. A continuous query named
+// "cq_" followed by the aggregate retention policy writes the mean of
+// every field in the raw retention policy into the aggregate retention
+// policy for each interval. The durations can be changed later with
+// ALTER RETENTION POLICY. Existing retention policies with different
+// settings, or an existing continuous query, return an error
+func (this *Client) SetupDownsampling(db, rawRP, aggRP string, every time.Duration) error {
+	if this.client == nil {
+		return influxdb.ErrNotConnected
+	} else if db == "" || rawRP == "" || aggRP == "" || rawRP == aggRP || every <= 0 {
+		return influxdb.ErrBadParameter
+	}
+	statements := []influxdb.Query{
+		influxdb.CreateDatabase(db),
+		influxdb.CreateRetentionPolicy(db, rawRP, &influxdb.RetentionPolicy{Duration: downsampleRawDuration}).Default(true),
+		influxdb.CreateRetentionPolicy(db, aggRP, &influxdb.RetentionPolicy{}),
+		influxdb.CreateContinuousQuery(db, "cq_"+aggRP, influxdb.DownsampleAll(db, rawRP, aggRP, every)),
+	}
+	for _, statement := range statements {
+		if _, err := this.Do(statement); err != nil && err != influxdb.ErrEmptyResponse {
+			return err
+		}
 	}
 	return nil
 }
@@ -263,39 +580,247 @@ func (this *Client) RetentionPolicies() (map[string]*influxdb.RetentionPolicy, e
 	}
 }
 
+// DefaultRetentionPolicy returns the name of the default retention policy
+// for a database, or for the current database when the database is empty.
+// Returns an error wrapping ErrNotFound if no policy is the default
+func (this *Client) DefaultRetentionPolicy(db string) (string, error) {
+	if this.client == nil {
+		return "", influxdb.ErrNotConnected
+	}
+	if db == "" {
+		db = this.database
+	}
+	if db == "" {
+		return "", influxdb.ErrBadParameter
+	}
+	policies, err := this.policiesOn(db)
+	if err != nil {
+		return "", err
+	}
+	for name, policy := range policies {
+		if policy.Default {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("%w: no default retention policy on %v", influxdb.ErrNotFound, db)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Manage running queries
+
+// ShowQueries returns the queries currently running on the server
+func (this *Client) ShowQueries() ([]*influxdb.RunningQuery, error) {
+	if this.client == nil {
+		return nil, influxdb.ErrNotConnected
+	}
+	if results, err := this.Do(influxdb.ShowQueries()); err != nil {
+		return nil, err
+	} else if len(results) != 1 {
+		return nil, influxdb.ErrUnexpectedResponse
+	} else {
+		return results[0].ParseRunningQueries()
+	}
+}
+
+// KillQuery stops a running query on the server. When dry run is enabled
+// the query is not killed and a DryRunError is returned
+func (this *Client) KillQuery(qid uint64) error {
+	if this.client == nil {
+		return influxdb.ErrNotConnected
+	}
+	return this.destroy(influxdb.KillQuery(qid))
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // Execute queries and re-format results
 
+// Do executes a query and returns the results. When the server marks any
+// series as partial (for example a truncated chunked response) the
+// results are returned along with ErrPartialResult, so that the caller
+// can decide whether incomplete data is acceptable
 func (this *Client) Do(query influxdb.Query) (influxdb.Results, error) {
 	if this.client == nil {
 		return nil, influxdb.ErrNotConnected
 	}
+	// Return cached results
+	statement := query.String()
+	if this.cache != nil && cacheable(statement) {
+		if r := this.cache.get(this.database, statement); r != nil {
+			return r, nil
+		}
+	}
 	// Query and sanity check the response
-	response, err := this.query(query.String())
+	response, err := this.query(statement)
 	if err != nil {
 		return nil, err
 	}
-	if len(response.Results) == 0 {
-		return nil, influxdb.ErrEmptyResponse
+	r, err := results(response)
+	if err == nil && this.cache != nil && cacheable(statement) {
+		this.cache.set(this.database, statement, r)
 	}
-	if response.Results[0].Series == nil || len(response.Results[0].Series) == 0 {
-		return nil, influxdb.ErrEmptyResponse
+	return r, err
+}
+
+// QueryRaw executes a query and returns the influxdata client response
+// directly, including all results, messages and errors for each
+// result. It is intended for features which the typed API does not
+// cover. When a result contains an error, the response is returned along
+// with the first error
+func (this *Client) QueryRaw(query string) (*client.Response, error) {
+	if this.client == nil {
+		return nil, influxdb.ErrNotConnected
 	}
-	r := make([]*influxdb.Result, 0, len(response.Results))
-	for i, result := range response.Results {
-		for j, series := range result.Series {
-			table := new(influxdb.Result)
-			table.Result = i
-			table.Series = j
-			table.Name = series.Name
-			table.Tags = series.Tags
-			table.Columns = series.Columns
-			table.Values = series.Values
-			table.Partial = series.Partial
-			r = append(r, table)
+	return this.query(query)
+}
+
+// QueryOn executes a query on a database without changing the current
+// database, so that the query does not affect other users of the client.
+// The database is not checked for existence
+func (this *Client) QueryOn(database string, query influxdb.Query) (influxdb.Results, error) {
+	if this.client == nil {
+		return nil, influxdb.ErrNotConnected
+	} else if database == "" {
+		return nil, influxdb.ErrBadParameter
+	}
+	other := *this
+	other.database = database
+	return other.Do(query)
+}
+
+// QueryOrEmpty executes a query which returns a single series, and
+// returns an empty result with no columns or rows instead of
+// ErrEmptyResponse when the query matches no data. The columns of an
+// empty result are not known, since the server does not return them.
+// Returns ErrUnexpectedResponse when the query returns more than one
+// series, and the result with ErrPartialResult when it is truncated
+func (this *Client) QueryOrEmpty(query string) (*influxdb.Result, error) {
+	result, _, err := this.queryOne(query)
+	return result, err
+}
+
+// QueryWithMessages executes a query which returns a single series as
+// QueryOrEmpty does, and also returns the messages which the server
+// included in the response, such as deprecation warnings, in the form
+// "level: text"
+func (this *Client) QueryWithMessages(query string) (*influxdb.Result, []string, error) {
+	return this.queryOne(query)
+}
+
+// QueryGrouped executes a query and returns the series in the results
+// keyed by their series key, so that each series of a grouped query can
+// be processed separately. Series with the same key in more than one
+// statement are combined when they have the same columns. A query which
+// returns no data returns an empty map. When the server marks any series
+// as partial, returns the series with ErrPartialResult
+func (this *Client) QueryGrouped(query string) (map[string]*influxdb.Result, error) {
+	if this.client == nil {
+		return nil, influxdb.ErrNotConnected
+	}
+	response, err := this.query(query)
+	if err != nil {
+		return nil, err
+	}
+	r, err := results(response)
+	if err == influxdb.ErrEmptyResponse {
+		return map[string]*influxdb.Result{}, nil
+	} else if err != nil && err != influxdb.ErrPartialResult {
+		return nil, err
+	}
+	grouped := make(map[string]*influxdb.Result, len(r))
+	for _, result := range r {
+		key := result.SeriesKey()
+		if other, exists := grouped[key]; exists == false {
+			grouped[key] = result
+		} else if strings.Join(other.Columns, ",") != strings.Join(result.Columns, ",") {
+			return nil, fmt.Errorf("%w: series %v has columns %v and %v", influxdb.ErrUnexpectedResponse, key, other.Columns, result.Columns)
+		} else {
+			other.Values = append(other.Values, result.Values...)
+			other.Partial = other.Partial || result.Partial
 		}
 	}
-	return influxdb.Results(r), nil
+	return grouped, err
+}
+
+// QueryIter executes a query and returns an iterator over the rows of all
+// the series in the results, which converts values as they are scanned.
+// A query which returns no data returns an iterator with no rows. When
+// the server marks any series as partial, the iterator Err method
+// returns ErrPartialResult
+func (this *Client) QueryIter(query string) (*influxdb.RowIter, error) {
+	if this.client == nil {
+		return nil, influxdb.ErrNotConnected
+	}
+	response, err := this.query(query)
+	if err != nil {
+		return nil, err
+	}
+	r, err := results(response)
+	if err == influxdb.ErrEmptyResponse {
+		return influxdb.NewRowIter(nil, nil), nil
+	} else if err != nil && err != influxdb.ErrPartialResult {
+		return nil, err
+	}
+	return influxdb.NewRowIter(r, err), nil
+}
+
+// QueryWithServerTimeout executes a query which is cancelled when it does
+// not complete within the timeout, returning context.DeadlineExceeded. On
+// timeout a best-effort KILL QUERY is issued so that the query does not
+// continue to consume resources on the server. The server does not report
+// which client issued a query, so the query to kill is the one with the
+// same statement and database which has been running for at least the
+// timeout and for the shortest time. If another client started an
+// identical query at about the same time, that query may be killed
+// instead
+func (this *Client) QueryWithServerTimeout(query string, timeout time.Duration) (influxdb.Results, error) {
+	if this.client == nil {
+		return nil, influxdb.ErrNotConnected
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	response, err := this.queryContext(ctx, query)
+	if err == context.DeadlineExceeded {
+		if err := this.killQuery(query, timeout); err != nil {
+			this.log.Warn("QueryWithServerTimeout: Unable to kill query: %v", err)
+		}
+		return nil, err
+	} else if err != nil {
+		return nil, err
+	}
+	return results(response)
+}
+
+// QueryConcurrent executes independent queries with up to maxParallel
+// queries in flight at once. The results are returned in the same order as
+// the queries. The errors for all failed queries are combined into the
+// returned error. The results for a failed query are nil, except for
+// partial results which are returned along with ErrPartialResult
+func (this *Client) QueryConcurrent(queries []influxdb.Query, maxParallel int) ([]influxdb.Results, error) {
+	if this.client == nil {
+		return nil, influxdb.ErrNotConnected
+	}
+	if maxParallel <= 0 {
+		return nil, influxdb.ErrBadParameter
+	}
+	results := make([]influxdb.Results, len(queries))
+	errs := make([]error, len(queries))
+	slots := make(chan struct{}, maxParallel)
+	var wg sync.WaitGroup
+	for i, query := range queries {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int, query influxdb.Query) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			r, err := this.Do(query)
+			results[i] = r
+			if err != nil {
+				errs[i] = fmt.Errorf("query %v: %w", i, err)
+			}
+		}(i, query)
+	}
+	wg.Wait()
+	return results, errors.Join(errs...)
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -312,27 +837,68 @@ func (this *Client) String() string {
 ////////////////////////////////////////////////////////////////////////////////
 // PRIVATE METHODS
 
-// Query database and return response or error
+// Query database and return response or error. When the server returns
+// an error in the response, the response is returned with the error.
+// The query is made with the HTTP client rather than the influxdata
+// client, since the influxdata client cannot add headers, has its own
+// transport and does not retry throttled requests
 func (this *Client) query(query string) (*client.Response, error) {
-	if this.database != "" {
-		this.log.Debug("<influxdb.Query>{ database=%v, q=%v }", this.database, query)
-	} else {
-		this.log.Debug("<influxdb.Query>{ database=<nil>, q=%v }", query)
-	}
-	response, err := this.client.Query(client.Query{
-		Command:   query,
-		Database:  this.database,
-		Precision: this.precision,
-	})
+	response, err := this.queryContext(context.Background(), query)
 	if err != nil {
 		return nil, err
 	}
 	if response.Error() != nil {
-		return nil, response.Error()
+		return response, response.Error()
+	}
+	if this.version.get() == "" {
+		this.updateVersion()
 	}
 	return response, nil
 }
 
+// pingVersion pings the server and returns the round trip time and the
+// server version
+func (this *Client) pingVersion() (time.Duration, string, error) {
+	ctx := context.Background()
+	if this.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, this.config.Timeout)
+		defer cancel()
+	}
+	start := time.Now()
+	resp, err := this.get(ctx, "ping")
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		return 0, "", fmt.Errorf("%w: %v", influxdb.ErrUnexpectedResponse, resp.Status)
+	}
+	return time.Since(start), resp.Header.Get("X-Influxdb-Version"), nil
+}
+
+// updateVersion pings the server to set the version when the ping was
+// skipped on Open
+func (this *Client) updateVersion() {
+	if _, version, err := this.pingVersion(); err != nil {
+		this.log.Warn("Unable to determine InfluxDB version: %v", err)
+	} else {
+		this.version.set(version)
+	}
+}
+
+func (v *serverVersion) get() string {
+	v.RLock()
+	defer v.RUnlock()
+	return v.value
+}
+
+func (v *serverVersion) set(value string) {
+	v.Lock()
+	defer v.Unlock()
+	v.value = value
+}
+
 func (this *Client) exists_string(q influxdb.Query, series string, column string, value string) (bool, error) {
 	if response, err := this.Do(q); err != nil {
 		return false, err
@@ -347,3 +913,136 @@ func (this *Client) exists_string(q influxdb.Query, series string, column string
 		return false, nil
 	}
 }
+
+// destroy executes a destructive query. When dry run is enabled the query
+// is logged and returned in a DryRunError instead
+func (this *Client) destroy(query influxdb.Query) error {
+	if this.dryrun {
+		statement := query.String()
+		this.log.Info("DryRun: %v", statement)
+		return &influxdb.DryRunError{Statement: statement}
+	}
+	if _, err := this.Do(query); err != nil && err != influxdb.ErrEmptyResponse {
+		return err
+	}
+	return nil
+}
+
+// killQuery finds a running query on the server with the same statement
+// and database and kills it. When several match, the query which has been
+// running for at least the timeout and for the shortest time is killed,
+// since it is most likely to be the query which timed out, or otherwise
+// the longest-running query, since durations are reported by the server
+// in whole units
+func (this *Client) killQuery(statement string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), killTimeout)
+	defer cancel()
+	response, err := this.queryContext(ctx, influxdb.ShowQueries().String())
+	if err != nil {
+		return err
+	}
+	r, err := results(response)
+	if err != nil {
+		return err
+	}
+	queries, err := r[0].ParseRunningQueries()
+	if err != nil {
+		return err
+	}
+	var match *influxdb.RunningQuery
+	for _, query := range queries {
+		if query.Query != statement || query.Database != this.database {
+			continue
+		}
+		if match == nil {
+			match = query
+		} else if query.Duration >= timeout && (match.Duration < timeout || query.Duration < match.Duration) {
+			match = query
+		} else if query.Duration < timeout && match.Duration < timeout && query.Duration > match.Duration {
+			match = query
+		}
+	}
+	if match == nil {
+		return influxdb.ErrNotFound
+	}
+	_, err = this.queryContext(ctx, influxdb.KillQuery(match.QID).String())
+	return err
+}
+
+// results converts a server response into a set of results, returning
+// ErrPartialResult with the results if any series is partial
+func results(response *client.Response) (influxdb.Results, error) {
+	if len(response.Results) == 0 {
+		return nil, influxdb.ErrEmptyResponse
+	}
+	if response.Results[0].Series == nil || len(response.Results[0].Series) == 0 {
+		return nil, influxdb.ErrEmptyResponse
+	}
+	r := make([]*influxdb.Result, 0, len(response.Results))
+	partial := false
+	for i, result := range response.Results {
+		for j, series := range result.Series {
+			table := new(influxdb.Result)
+			table.Result = i
+			table.Series = j
+			table.Name = series.Name
+			table.Tags = series.Tags
+			table.Columns = series.Columns
+			table.Values = series.Values
+			table.Partial = series.Partial
+			r = append(r, table)
+			partial = partial || series.Partial
+		}
+	}
+	if partial {
+		return influxdb.Results(r), influxdb.ErrPartialResult
+	}
+	return influxdb.Results(r), nil
+}
+
+// queryOne executes a query which returns a single series, returning an
+// empty result when the query matches no data, and the messages in the
+// response
+func (this *Client) queryOne(query string) (*influxdb.Result, []string, error) {
+	if this.client == nil {
+		return nil, nil, influxdb.ErrNotConnected
+	}
+	response, err := this.query(query)
+	if err != nil {
+		return nil, nil, err
+	}
+	messages := make([]string, 0)
+	for _, result := range response.Results {
+		for _, message := range result.Messages {
+			messages = append(messages, message.Level+": "+message.Text)
+		}
+	}
+	r, err := results(response)
+	if err == influxdb.ErrEmptyResponse {
+		return &influxdb.Result{Columns: []string{}, Values: [][]interface{}{}}, messages, nil
+	} else if err != nil && err != influxdb.ErrPartialResult {
+		return nil, messages, err
+	} else if len(r) != 1 {
+		return nil, messages, fmt.Errorf("%w: expected one series, got %v", influxdb.ErrUnexpectedResponse, len(r))
+	}
+	return r[0], messages, err
+}
+
+// redactQuery returns a statement for logging, with any password replaced
+func redactQuery(query string) string {
+	return regexpPassword.ReplaceAllString(query, "${1}'"+redacted+"'")
+}
+
+// parseVersion returns the major and minor parts of a version string
+// such as "1.8.10" or "v2.0.4", and false if the string is malformed
+func parseVersion(value string) (int, int, bool) {
+	if match := regexpVersion.FindStringSubmatch(strings.TrimSpace(value)); match == nil {
+		return 0, 0, false
+	} else if major, err := strconv.Atoi(match[1]); err != nil {
+		return 0, 0, false
+	} else if minor, err := strconv.Atoi(match[2]); err != nil {
+		return 0, 0, false
+	} else {
+		return major, minor, true
+	}
+}
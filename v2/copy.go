@@ -0,0 +1,296 @@
+/*
+	InfluxDB client
+	(c) Copyright David Thorpe 2017
+	All Rights Reserved
+
+	For Licensing and Usage information, please see LICENSE file
+*/
+
+package v2
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	influxdb "github.com/djthorpe/influxdb"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// COPY DATA
+
+// CopyMeasurement copies the points in the source measurement which match
+// the predicates into the destination measurement, preserving tags. Either
+// name may be qualified with a retention policy and database. InfluxDB
+// cannot rename measurements, so for a rename the caller should drop the
+// source measurement once the copy has completed
+func (this *Client) CopyMeasurement(src, dst string, where ...influxdb.Predicate) error {
+	if this.client == nil {
+		return influxdb.ErrNotConnected
+	}
+	if src_, err := influxdb.ParseMeasurement(src); err != nil {
+		return err
+	} else if dst_, err := influxdb.ParseMeasurement(dst); err != nil {
+		return err
+	} else if _, err := this.Do(influxdb.Select(src_).Into(dst_).Filter(where...).GroupBy("*")); err != nil && err != influxdb.ErrEmptyResponse {
+		return err
+	}
+	return nil
+}
+
+// CopyDatabase copies measurements from the source database to the
+// default retention policy of the destination database, preserving tags.
+// When measurements is empty, all measurements in the source database are
+// copied. Progress is logged for each measurement, and copying stops at
+// the first measurement which fails
+func (this *Client) CopyDatabase(src, dst string, measurements []string) error {
+	if this.client == nil {
+		return influxdb.ErrNotConnected
+	}
+	if src == "" || dst == "" {
+		return influxdb.ErrBadParameter
+	}
+	if len(measurements) == 0 {
+		var err error
+		if measurements, err = this.GetMeasurements(src); err != nil {
+			return err
+		}
+	}
+	for i, measurement := range measurements {
+		this.log.Info("CopyDatabase: %v (%v of %v)", measurement, i+1, len(measurements))
+		q := influxdb.Select(&influxdb.Measurement{Database: src, Name: measurement}).Into(&influxdb.Measurement{Database: dst, Name: measurement}).GroupBy("*")
+		if _, err := this.Do(q); err != nil && err != influxdb.ErrEmptyResponse {
+			return fmt.Errorf("%v: %w", measurement, err)
+		}
+	}
+	return nil
+}
+
+// MigrateToPolicy copies the points in a measurement from one retention
+// policy to another, preserving tags, so that data can be kept for longer
+// by moving it to a policy with a longer duration. The measurement may be
+// qualified with a database, otherwise the current database is used. Both
+// retention policies must exist, or an error wrapping ErrNotFound is
+// returned. The points in the source retention policy are not removed
+func (this *Client) MigrateToPolicy(measurement, fromRP, toRP string) error {
+	if this.client == nil {
+		return influxdb.ErrNotConnected
+	}
+	m, err := influxdb.ParseMeasurement(measurement)
+	if err != nil {
+		return err
+	} else if m.Policy != "" || fromRP == "" || toRP == "" || fromRP == toRP {
+		return influxdb.ErrBadParameter
+	}
+	if m.Database == "" {
+		m.Database = this.database
+	}
+	if m.Database == "" {
+		return influxdb.ErrBadParameter
+	}
+
+	// Check both retention policies exist
+	policies, err := this.policiesOn(m.Database)
+	if err != nil {
+		return err
+	}
+	for _, name := range []string{fromRP, toRP} {
+		if _, exists := policies[name]; exists == false {
+			return fmt.Errorf("%w: retention policy %q on %v", influxdb.ErrNotFound, name, m.Database)
+		}
+	}
+
+	// Copy the data
+	src := &influxdb.Measurement{Database: m.Database, Policy: fromRP, Name: m.Name}
+	dst := &influxdb.Measurement{Database: m.Database, Policy: toRP, Name: m.Name}
+	if _, err := this.Do(influxdb.Select(src).Into(dst).GroupBy("*")); err != nil && err != influxdb.ErrEmptyResponse {
+		return err
+	}
+	return nil
+}
+
+// CopyRetentionPolicy creates a retention policy on the destination
+// database with the same duration, replication factor and shard group
+// duration as the named retention policy on the source database, which is
+// also made the default when it is the default on the source. Returns an
+// error wrapping ErrNotFound if the source policy does not exist, or
+// ErrAlreadyExists if the destination database already has the policy
+func (this *Client) CopyRetentionPolicy(srcDB, rpName, dstDB string) error {
+	if this.client == nil {
+		return influxdb.ErrNotConnected
+	} else if srcDB == "" || rpName == "" || dstDB == "" || srcDB == dstDB {
+		return influxdb.ErrBadParameter
+	}
+
+	// Read the source and destination policies
+	src, err := this.policiesOn(srcDB)
+	if err != nil {
+		return err
+	}
+	policy, exists := src[rpName]
+	if exists == false {
+		return fmt.Errorf("%w: retention policy %q on %v", influxdb.ErrNotFound, rpName, srcDB)
+	}
+	if dst, err := this.policiesOn(dstDB); err != nil {
+		return err
+	} else if _, exists := dst[rpName]; exists {
+		return fmt.Errorf("%w: retention policy %q on %v", influxdb.ErrAlreadyExists, rpName, dstDB)
+	}
+
+	// Create the policy
+	if _, err := this.Do(influxdb.CreateRetentionPolicy(dstDB, rpName, policy).Default(policy.Default)); err != nil && err != influxdb.ErrEmptyResponse {
+		return err
+	}
+	return nil
+}
+
+// RenameTag renames a tag key in a measurement, which may be qualified with
+// a database and retention policy. InfluxDB cannot rename tags in place,
+// so the series with the tag are read, written back with the new tag key,
+// and then dropped. Because this rewrites data, confirm must be
+// ConfirmRenameTag or ErrConfirmationRequired is returned, and when dry
+// run is enabled nothing is written and a DryRunError is returned.
+//
+// The series are read in a single query, so the measurement must fit in
+// memory, and points written to the old series while renaming are lost.
+// While renaming, each series exists with both the old and new keys, which
+// doubles the series cardinality of the measurement until the old series
+// are dropped. Renaming to a key which already exists is not allowed,
+// since it would merge series
+func (this *Client) RenameTag(measurement, oldKey, newKey string, confirm influxdb.Confirmation) error {
+	if this.client == nil {
+		return influxdb.ErrNotConnected
+	} else if confirm != influxdb.ConfirmRenameTag {
+		return influxdb.ErrConfirmationRequired
+	} else if oldKey == "" || newKey == "" || oldKey == newKey {
+		return influxdb.ErrBadParameter
+	}
+	m, err := influxdb.ParseMeasurement(measurement)
+	if err != nil {
+		return err
+	}
+
+	// Check the old tag exists and the new one does not
+	var keys []string
+	if results, err := this.Do(influxdb.ShowTagKeys().Measurement(m)); err != nil && err != influxdb.ErrEmptyResponse {
+		return err
+	} else if len(results) > 1 {
+		return influxdb.ErrUnexpectedResponse
+	} else if len(results) == 1 {
+		if keys, err = results[0].ParseTagKeys(); err != nil {
+			return err
+		}
+	}
+	found := false
+	for _, key := range keys {
+		if key == newKey {
+			return fmt.Errorf("%w: tag %q on %v", influxdb.ErrAlreadyExists, newKey, m)
+		} else if key == oldKey {
+			found = true
+		}
+	}
+	if found == false {
+		return fmt.Errorf("%w: tag %q on %v", influxdb.ErrNotFound, oldKey, m)
+	}
+	fields, err := this.ShowFieldKeys(measurement)
+	if err != nil {
+		return err
+	} else if _, exists := fields[newKey]; exists {
+		return fmt.Errorf("%w: field %q on %v", influxdb.ErrAlreadyExists, newKey, m)
+	}
+
+	// Drop the series with the old tag once they have been rewritten
+	where, err := influxdb.WhereTagRegex(oldKey, ".+")
+	if err != nil {
+		return err
+	}
+	drop := influxdb.DropSeries(m).Where(where)
+	if this.dryrun {
+		return this.destroy(drop)
+	}
+
+	// Read the series with the old tag and write them back
+	results, err := this.Do(influxdb.Select(m).Where(where).GroupBy("*"))
+	if err == influxdb.ErrEmptyResponse {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	points := make([]*influxdb.Point, 0, renameBatchSize)
+	for _, result := range results {
+		tags := make(map[string]string, len(result.Tags))
+		for key, value := range result.Tags {
+			if key == oldKey {
+				tags[newKey] = value
+			} else {
+				tags[key] = value
+			}
+		}
+		for _, row := range result.Values {
+			if point, err := renamePoint(measurement, tags, fields, result, row); err != nil {
+				return err
+			} else if point != nil {
+				points = append(points, point)
+			}
+			if len(points) >= renameBatchSize {
+				if err := this.WriteBatch(points); err != nil {
+					return err
+				}
+				points = points[:0]
+			}
+		}
+	}
+	if len(points) > 0 {
+		if err := this.WriteBatch(points); err != nil {
+			return err
+		}
+	}
+	this.log.Info("RenameTag: %v: %q renamed to %q", m, oldKey, newKey)
+	return this.destroy(drop)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// policiesOn returns the retention policies on a database
+func (this *Client) policiesOn(database string) (map[string]*influxdb.RetentionPolicy, error) {
+	if results, err := this.Do(influxdb.ShowRetentionPolicies().Database(database)); err != nil {
+		return nil, err
+	} else if len(results) != 1 {
+		return nil, influxdb.ErrUnexpectedResponse
+	} else {
+		return results[0].ParseRetentionPolicies()
+	}
+}
+
+// renamePoint returns the point for a row read by RenameTag, converting
+// field values to the types of the existing fields, or nil if the row has
+// no field values
+func renamePoint(measurement string, tags map[string]string, fields map[string]string, result *influxdb.Result, row []interface{}) (*influxdb.Point, error) {
+	ts, err := rowTime(result, row)
+	if err != nil {
+		return nil, err
+	}
+	point := &influxdb.Point{Measurement: measurement, Tags: tags, Fields: make(map[string]interface{}, len(row)), Time: ts}
+	for i, value := range row {
+		if i >= len(result.Columns) || result.Columns[i] == "time" || value == nil {
+			continue
+		} else if number, ok := value.(json.Number); ok == false {
+			point.Fields[result.Columns[i]] = value
+		} else if field_type := fields[result.Columns[i]]; field_type == "integer" {
+			if point.Fields[result.Columns[i]], err = number.Int64(); err != nil {
+				return nil, influxdb.ErrUnexpectedResponse
+			}
+		} else if field_type == "unsigned" {
+			if point.Fields[result.Columns[i]], err = strconv.ParseUint(number.String(), 10, 64); err != nil {
+				return nil, influxdb.ErrUnexpectedResponse
+			}
+		} else if point.Fields[result.Columns[i]], err = number.Float64(); err != nil {
+			return nil, influxdb.ErrUnexpectedResponse
+		}
+	}
+	if len(point.Fields) == 0 {
+		return nil, nil
+	}
+	return point, nil
+}
@@ -0,0 +1,141 @@
+/*
+	InfluxDB client
+	(c) Copyright David Thorpe 2017
+	All Rights Reserved
+
+	For Licensing and Usage information, please see LICENSE file
+*/
+
+package v2
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	influxdb "github.com/djthorpe/influxdb"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// cursor pages through a measurement in time order. The position is the
+// timestamp of the last row read and the number of rows read with that
+// timestamp, so that rows sharing a timestamp across a page boundary
+// are neither skipped nor repeated
+type cursor struct {
+	client      *Client
+	measurement *influxdb.Measurement
+	size        uint
+	time        time.Time
+	skip        uint
+	done        bool
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTRUCTOR
+
+// ExportCursor returns a cursor which reads a measurement pageSize rows at
+// a time, from the earliest point. The measurement may be qualified with
+// a database and retention policy
+func (this *Client) ExportCursor(measurement string, pageSize int) (influxdb.Cursor, error) {
+	if this.client == nil {
+		return nil, influxdb.ErrNotConnected
+	} else if pageSize <= 0 {
+		return nil, influxdb.ErrBadParameter
+	}
+	m, err := influxdb.ParseMeasurement(measurement)
+	if err != nil {
+		return nil, err
+	}
+	return &cursor{client: this, measurement: m, size: uint(pageSize)}, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Next returns the next page of rows, or false when there are no more rows
+func (this *cursor) Next() (*influxdb.Result, bool, error) {
+	if this.done {
+		return nil, false, nil
+	}
+	q := influxdb.Select(this.measurement).OffsetLimit(this.skip, this.size)
+	if this.time.IsZero() == false {
+		q = q.Where(influxdb.Not(influxdb.LessThan("time", this.time)))
+	}
+	r, err := this.client.Do(q)
+	if err == influxdb.ErrEmptyResponse {
+		this.done = true
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
+	} else if len(r) != 1 {
+		return nil, false, influxdb.ErrUnexpectedResponse
+	}
+
+	// Advance the position to the last row
+	page := r[0]
+	for _, row := range page.Values {
+		if ts, err := rowTime(page, row); err != nil {
+			return nil, false, err
+		} else if ts.Equal(this.time) {
+			this.skip++
+		} else {
+			this.time, this.skip = ts, 1
+		}
+	}
+	if uint(len(page.Values)) < this.size {
+		this.done = true
+	}
+	return page, true, nil
+}
+
+// Position returns a token for the position after the last row read
+func (this *cursor) Position() string {
+	if this.time.IsZero() {
+		return ""
+	}
+	return this.time.UTC().Format(time.RFC3339Nano) + "#" + fmt.Sprint(this.skip)
+}
+
+// Seek sets the position from a token returned by Position. An empty
+// token is the start of the measurement
+func (this *cursor) Seek(position string) error {
+	if position == "" {
+		this.time, this.skip, this.done = time.Time{}, 0, false
+		return nil
+	}
+	parts := strings.SplitN(position, "#", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("%w: invalid position %q", influxdb.ErrBadParameter, position)
+	} else if ts, err := time.Parse(time.RFC3339Nano, parts[0]); err != nil {
+		return fmt.Errorf("%w: invalid position %q", influxdb.ErrBadParameter, position)
+	} else if skip, err := strconv.ParseUint(parts[1], 10, 32); err != nil {
+		return fmt.Errorf("%w: invalid position %q", influxdb.ErrBadParameter, position)
+	} else {
+		this.time, this.skip, this.done = ts, uint(skip), false
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// rowTime returns the timestamp of a row
+func rowTime(result *influxdb.Result, row []interface{}) (time.Time, error) {
+	for i, column := range result.Columns {
+		if column != "time" {
+			continue
+		} else if i >= len(row) {
+			break
+		} else if str, ok := row[i].(string); ok == false {
+			break
+		} else if ts, err := time.Parse(time.RFC3339Nano, str); err != nil {
+			break
+		} else {
+			return ts, nil
+		}
+	}
+	return time.Time{}, influxdb.ErrUnexpectedResponse
+}
@@ -34,7 +34,7 @@ type dataset struct {
 // CONSTRUCTOR
 
 // NewDataset returns an empty dataset object used for writing
-func (this *Client) NewDataset(name string, fields ...string) (influxdb.Dataset, error) {
+func (this *Client) NewDataset(name string, tags, fields []string) (influxdb.Dataset, error) {
 	d := new(dataset)
 
 	// Set measurement name and database name
@@ -53,14 +53,19 @@ func (this *Client) NewDataset(name string, fields ...string) (influxdb.Dataset,
 	}
 
 	// tags and fields
-	d.tags = make(map[string]string, 0)
+	d.tags = make(map[string]string, len(tags))
+	for _, tag := range tags {
+		d.tags[tag] = ""
+	}
 	d.fields = make([]string, 0, len(fields))
 	d.fields = append(d.fields, fields...)
 
 	// create batch points
 	if points, err := v2.NewBatchPoints(v2.BatchPointsConfig{
-		Database:  d.database,
-		Precision: d.precision,
+		Database:         d.database,
+		Precision:        d.precision,
+		RetentionPolicy:  this.policy,
+		WriteConsistency: this.consistency,
 	}); err != nil {
 		return nil, err
 	} else {
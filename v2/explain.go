@@ -0,0 +1,53 @@
+/*
+	InfluxDB client
+	(c) Copyright David Thorpe 2017
+	All Rights Reserved
+
+	For Licensing and Usage information, please see LICENSE file
+*/
+
+package v2
+
+import (
+	"fmt"
+	"strings"
+
+	influxdb "github.com/djthorpe/influxdb"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// EXPLAIN
+
+// ExplainQuery returns the query plan for a SELECT statement without
+// executing it, with one row per line of the plan in the "QUERY PLAN"
+// column. Returns ErrNotSupported for servers without EXPLAIN, which was
+// added in InfluxDB 1.6
+func (this *Client) ExplainQuery(query string) (*influxdb.Result, error) {
+	return this.explain("EXPLAIN", query)
+}
+
+// AnalyzeQuery executes a SELECT statement and returns the query plan with
+// the time taken and resources used by each step of the plan, discarding
+// the results of the query. Returns ErrNotSupported for servers without
+// EXPLAIN ANALYZE
+func (this *Client) AnalyzeQuery(query string) (*influxdb.Result, error) {
+	return this.explain("EXPLAIN ANALYZE", query)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+func (this *Client) explain(prefix, query string) (*influxdb.Result, error) {
+	if this.client == nil {
+		return nil, influxdb.ErrNotConnected
+	} else if query = strings.TrimSpace(query); query == "" {
+		return nil, influxdb.ErrBadParameter
+	}
+	result, err := this.QueryOrEmpty(prefix + " " + query)
+	if err != nil && strings.Contains(err.Error(), "found EXPLAIN") {
+		return nil, fmt.Errorf("%w: %v (server version %v)", influxdb.ErrNotSupported, prefix, this.Version())
+	} else if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
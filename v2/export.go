@@ -0,0 +1,213 @@
+/*
+	InfluxDB client
+	(c) Copyright David Thorpe 2017
+	All Rights Reserved
+
+	For Licensing and Usage information, please see LICENSE file
+*/
+
+package v2
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	influxdb "github.com/djthorpe/influxdb"
+	client "github.com/influxdata/influxdb/client/v2"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// EXPORT
+
+// ExportCSV executes a query and writes the results to w as CSV, reading
+// the response in chunks of chunkSize rows so that large results are
+// written without being held in memory. The header is written once, from
+// the columns of the first series. Returns ErrUnexpectedResponse if a
+// later series has different columns
+func (this *Client) ExportCSV(query string, w io.Writer, chunkSize int) error {
+	if this.client == nil {
+		return influxdb.ErrNotConnected
+	} else if w == nil || chunkSize <= 0 {
+		return influxdb.ErrBadParameter
+	}
+	resp, err := this.queryChunked(context.Background(), query, chunkSize)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	out := csv.NewWriter(w)
+	decoder := json.NewDecoder(resp.Body)
+	decoder.UseNumber()
+	var columns []string
+	for {
+		chunk := new(client.Response)
+		if err := decoder.Decode(chunk); err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		} else if err := chunk.Error(); err != nil {
+			return err
+		}
+		for _, result := range chunk.Results {
+			for _, series := range result.Series {
+				if columns == nil {
+					columns = series.Columns
+					if err := out.Write(columns); err != nil {
+						return err
+					}
+				} else if strings.Join(series.Columns, ",") != strings.Join(columns, ",") {
+					return fmt.Errorf("%w: series %v has columns %v", influxdb.ErrUnexpectedResponse, series.Name, series.Columns)
+				}
+				if err := writeCSV(out, series.Values); err != nil {
+					return err
+				}
+			}
+		}
+		// Write each chunk through to the writer
+		out.Flush()
+		if err := out.Error(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExportSeriesByGroup reads a measurement grouped by all tags, which may
+// be qualified with a database and retention policy, seriesPerPage series
+// at a time using SLIMIT and SOFFSET, and calls fn with each series so
+// that high cardinality measurements are not read in a single response.
+// Stops and returns the error when fn returns an error
+func (this *Client) ExportSeriesByGroup(measurement string, seriesPerPage int, fn func(*influxdb.Result) error) error {
+	if this.client == nil {
+		return influxdb.ErrNotConnected
+	} else if seriesPerPage <= 0 || fn == nil {
+		return influxdb.ErrBadParameter
+	}
+	m, err := influxdb.ParseMeasurement(measurement)
+	if err != nil {
+		return err
+	}
+	for offset := 0; ; offset += seriesPerPage {
+		r, err := this.Do(influxdb.Select(m).GroupBy("*").SeriesOffsetLimit(uint(offset), uint(seriesPerPage)))
+		if err == influxdb.ErrEmptyResponse {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		for _, series := range r {
+			if err := fn(series); err != nil {
+				return err
+			}
+		}
+		if len(r) < seriesPerPage {
+			return nil
+		}
+	}
+}
+
+// QueryCSV executes a query requesting the response as CSV, which is
+// parsed without decoding JSON. The first record is the header, which is
+// repeated when the series have different columns. Returns
+// ErrUnexpectedResponse if the server does not return CSV
+func (this *Client) QueryCSV(query string) ([][]string, error) {
+	if this.client == nil {
+		return nil, influxdb.ErrNotConnected
+	}
+	this.log.Debug("<influxdb.QueryCSV>{ database=%v, q=%v }", this.database, redactQuery(query))
+
+	resp, err := this.queryResponse(context.Background(), query, url.Values{}, "application/csv")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if content_type := resp.Header.Get("Content-Type"); strings.HasPrefix(content_type, "application/csv") == false && strings.HasPrefix(content_type, "text/csv") == false {
+		return nil, fmt.Errorf("%w: content type %q", influxdb.ErrUnexpectedResponse, content_type)
+	}
+
+	// Series with different columns have records of different lengths
+	reader := csv.NewReader(resp.Body)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", influxdb.ErrUnexpectedResponse, err)
+	}
+	return records, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// queryChunked performs a query requesting a chunked response, and returns
+// the HTTP response for the caller to read and close
+func (this *Client) queryChunked(ctx context.Context, query string, size int) (*http.Response, error) {
+	this.log.Debug("<influxdb.QueryChunked>{ database=%v, q=%v, chunk_size=%v }", this.database, redactQuery(query), size)
+
+	values := url.Values{}
+	values.Set("chunked", "true")
+	values.Set("chunk_size", fmt.Sprint(size))
+	return this.queryResponse(ctx, query, values, "")
+}
+
+// queryResponse performs a query with additional parameters and, when not
+// empty, an Accept header, and returns the HTTP response for the caller to
+// read and close
+func (this *Client) queryResponse(ctx context.Context, query string, values url.Values, accept string) (resp *http.Response, err error) {
+	defer func() { this.metrics.query(err) }()
+	values.Set("q", query)
+	if this.database != "" {
+		values.Set("db", this.database)
+	}
+	if this.precision != "" {
+		values.Set("epoch", this.precision)
+	}
+	req, err := http.NewRequest("POST", this.addr+"query?"+values.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if this.config.Username != "" {
+		req.SetBasicAuth(this.config.Username, this.config.Password)
+	}
+	resp, err = this.http.Do(req.WithContext(ctx))
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		response := new(client.Response)
+		if err := json.NewDecoder(resp.Body).Decode(response); err == nil && response.Error() != nil {
+			return nil, response.Error()
+		}
+		return nil, fmt.Errorf("%w: %v", influxdb.ErrUnexpectedResponse, resp.Status)
+	}
+	return resp, nil
+}
+
+// writeCSV writes rows of values as CSV records, with nil values as
+// empty fields
+func writeCSV(out *csv.Writer, rows [][]interface{}) error {
+	for _, row := range rows {
+		record := make([]string, len(row))
+		for i, value := range row {
+			if value != nil {
+				record[i] = fmt.Sprint(value)
+			}
+		}
+		if err := out.Write(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+/*
+	InfluxDB client
+	(c) Copyright David Thorpe 2017
+	All Rights Reserved
+
+	For Licensing and Usage information, please see LICENSE file
+*/
+
+package v2
+
+import (
+	"expvar"
+	"fmt"
+	"sync"
+
+	influxdb "github.com/djthorpe/influxdb"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// metrics counts the queries and writes made by a client and their
+// errors, which are shared between copies of a client
+type metrics struct {
+	sync.Mutex
+	queries    int64
+	writes     int64
+	errors     int64
+	last_error string
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// GLOBALS
+
+// expvarLock serializes PublishExpvar, since expvar.Publish panics when a
+// name is published twice
+var expvarLock sync.Mutex
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// PublishExpvar publishes the number of queries, writes and errors and
+// the last error of the client as expvar variables, so that they are
+// reported by /debug/vars. The variables are named with the prefix
+// followed by ".queries", ".writes", ".errors" and ".last_error". Returns
+// ErrBadParameter for an empty prefix and an error wrapping
+// ErrAlreadyExists if a variable with one of the names is already
+// published, since expvar variables cannot be removed. It is safe to
+// call concurrently, in which case at most one call with a given prefix
+// succeeds
+func (this *Client) PublishExpvar(prefix string) error {
+	if this.client == nil {
+		return influxdb.ErrNotConnected
+	} else if prefix == "" {
+		return influxdb.ErrBadParameter
+	}
+	m := this.metrics
+	vars := map[string]expvar.Func{
+		prefix + ".queries":    m.value(func() interface{} { return m.queries }),
+		prefix + ".writes":     m.value(func() interface{} { return m.writes }),
+		prefix + ".errors":     m.value(func() interface{} { return m.errors }),
+		prefix + ".last_error": m.value(func() interface{} { return m.last_error }),
+	}
+	expvarLock.Lock()
+	defer expvarLock.Unlock()
+	for name := range vars {
+		if expvar.Get(name) != nil {
+			return fmt.Errorf("%w: expvar %q", influxdb.ErrAlreadyExists, name)
+		}
+	}
+	for name, value := range vars {
+		expvar.Publish(name, value)
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// query counts a query and returns its error
+func (m *metrics) query(err error) error {
+	m.Lock()
+	defer m.Unlock()
+	m.queries++
+	m.failed(err)
+	return err
+}
+
+// write counts a write and returns its error
+func (m *metrics) write(err error) error {
+	m.Lock()
+	defer m.Unlock()
+	m.writes++
+	m.failed(err)
+	return err
+}
+
+// failed counts an error and sets the last error when the error is not
+// nil. It is called with the lock held
+func (m *metrics) failed(err error) {
+	if err != nil {
+		m.errors++
+		m.last_error = err.Error()
+	}
+}
+
+// value returns an expvar function which reads a counter with the lock
+// held
+func (m *metrics) value(fn func() interface{}) expvar.Func {
+	return func() interface{} {
+		m.Lock()
+		defer m.Unlock()
+		return fn()
+	}
+}
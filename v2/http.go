@@ -0,0 +1,304 @@
+/*
+	InfluxDB client
+	(c) Copyright David Thorpe 2017
+	All Rights Reserved
+
+	For Licensing and Usage information, please see LICENSE file
+*/
+
+package v2
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	influxdb "github.com/djthorpe/influxdb"
+	client "github.com/influxdata/influxdb/client/v2"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// headerTransport adds headers to requests which do not already set them
+type headerTransport struct {
+	base    http.RoundTripper
+	headers map[string]string
+}
+
+// throttleTransport retries requests which the server rejects with 429
+// Too Many Requests, after the delay in the Retry-After header
+type throttleTransport struct {
+	base     http.RoundTripper
+	retries  int
+	maxDelay time.Duration
+}
+
+// health is the response from the /health endpoint
+type health struct {
+	Name    string `json:"name"`
+	Message string `json:"message"`
+	Status  string `json:"status"`
+	Version string `json:"version"`
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// HTTP CLIENT
+
+// newHTTPClient returns the HTTP client used for requests which need
+// more control than the influxdata client provides, such as cancellation
+// and custom headers. The transport may be shared with other clients
+func newHTTPClient(config client.HTTPConfig, headers map[string]string, transport http.RoundTripper) *http.Client {
+	transport = &throttleTransport{base: transport, retries: throttleRetries, maxDelay: throttleMaxDelay}
+	if len(headers) > 0 {
+		transport = &headerTransport{base: transport, headers: headers}
+	}
+	return &http.Client{
+		Timeout:   config.Timeout,
+		Transport: transport,
+	}
+}
+
+// newTransport returns a transport for the configuration, with the
+// default keep-alive and idle connection timeout when they are not set
+func newTransport(config Config) *http.Transport {
+	keepalive, idle := config.KeepAlive, config.IdleConnTimeout
+	if keepalive == 0 {
+		keepalive = defaultKeepAlive
+	}
+	if idle == 0 {
+		idle = defaultIdleConnTimeout
+	}
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			KeepAlive: keepalive,
+		}).DialContext,
+		IdleConnTimeout: idle,
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: (config.SSLVerify == false),
+		},
+	}
+}
+
+// RoundTrip adds the headers to a copy of the request, without replacing
+// any header which the request already sets
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for key, value := range t.headers {
+		if req.Header.Get(key) == "" {
+			req.Header.Set(key, value)
+		}
+	}
+	return t.base.RoundTrip(req)
+}
+
+// RoundTrip performs the request, and when the server responds with 429
+// Too Many Requests and a Retry-After header, waits for the delay and
+// retries the request. The response is returned when there is no
+// Retry-After header, the delay is longer than the maximum delay, the
+// request body cannot be sent again or the retries are exhausted.
+// Returns the context error if the request is cancelled while waiting
+func (t *throttleTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests || attempt >= t.retries {
+			return resp, err
+		}
+		delay, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now())
+		if ok == false || delay > t.maxDelay || (req.Body != nil && req.GetBody == nil) {
+			return resp, nil
+		}
+		next := req.Clone(req.Context())
+		if req.Body != nil {
+			if next.Body, err = req.GetBody(); err != nil {
+				return resp, nil
+			}
+		}
+		resp.Body.Close()
+
+		// Wait for the delay before the next attempt
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+		req = next
+	}
+}
+
+// retryAfter returns the delay in a Retry-After header, which is either
+// a number of seconds or a date, and false if there is no valid delay
+func retryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	} else if seconds, err := strconv.ParseUint(value, 10, 32); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	} else if date, err := http.ParseTime(value); err == nil {
+		if delay := date.Sub(now); delay > 0 {
+			return delay, true
+		}
+		return 0, true
+	} else {
+		return 0, false
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// HEALTH CHECK
+
+// HealthCheck returns nil if the server reports that it is healthy. The
+// /health endpoint is used when available (InfluxDB 1.8 and later), and
+// the /ping endpoint for older servers. Returns ErrUnhealthy when the
+// server reports it is not healthy
+func (this *Client) HealthCheck(ctx context.Context) error {
+	if this.client == nil {
+		return influxdb.ErrNotConnected
+	}
+	resp, err := this.get(ctx, "health")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return this.ping(ctx)
+	}
+	status := new(health)
+	if err := json.NewDecoder(resp.Body).Decode(status); err != nil {
+		return influxdb.ErrUnexpectedResponse
+	} else if status.Status != "pass" {
+		return fmt.Errorf("%w: %v", influxdb.ErrUnhealthy, status.Message)
+	} else if resp.StatusCode != http.StatusOK {
+		return influxdb.ErrUnhealthy
+	}
+	return nil
+}
+
+// WaitReady checks the health of the server on every interval until it
+// is healthy, returning nil, or until the context is done, when an error
+// wrapping the context error and the last health check error is
+// returned. The server does not need to be available when the client is
+// opened with SkipPing, so WaitReady can be used when the server and
+// client start together
+func (this *Client) WaitReady(ctx context.Context, interval time.Duration) error {
+	if this.client == nil {
+		return influxdb.ErrNotConnected
+	} else if interval <= 0 {
+		return influxdb.ErrBadParameter
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		err := this.HealthCheck(ctx)
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			if errors.Is(err, ctx.Err()) {
+				return err
+			}
+			return fmt.Errorf("%w: %v", ctx.Err(), err)
+		}
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// get performs a GET request on an endpoint with a context
+func (this *Client) get(ctx context.Context, path string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", this.addr+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if this.config.Username != "" {
+		req.SetBasicAuth(this.config.Username, this.config.Password)
+	}
+	if resp, err := this.http.Do(req.WithContext(ctx)); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	} else {
+		return resp, nil
+	}
+}
+
+// ping returns nil if the server responds to a ping
+func (this *Client) ping(ctx context.Context) error {
+	resp, err := this.get(ctx, "ping")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		return influxdb.ErrUnhealthy
+	}
+	return nil
+}
+
+// Query database with a context and return response or error. When the
+// context is cancelled or times out, the context error is returned. When
+// the server returns an error in the response, the response is returned
+// with the error
+func (this *Client) queryContext(ctx context.Context, query string) (response *client.Response, err error) {
+	defer func() { this.metrics.query(err) }()
+	if this.database != "" {
+		this.log.Debug("<influxdb.QueryContext>{ database=%v, q=%v }", this.database, redactQuery(query))
+	} else {
+		this.log.Debug("<influxdb.QueryContext>{ database=<nil>, q=%v }", redactQuery(query))
+	}
+
+	// Create the request
+	values := url.Values{}
+	values.Set("q", query)
+	if this.database != "" {
+		values.Set("db", this.database)
+	}
+	if this.precision != "" {
+		values.Set("epoch", this.precision)
+	}
+	req, err := http.NewRequest("POST", this.addr+"query?"+values.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if this.config.Username != "" {
+		req.SetBasicAuth(this.config.Username, this.config.Password)
+	}
+
+	// Perform the request, return context error on cancel or timeout
+	resp, err := this.http.Do(req.WithContext(ctx))
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Decode the response
+	response = new(client.Response)
+	decoder := json.NewDecoder(resp.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(response); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+	if response.Error() != nil {
+		return response, response.Error()
+	}
+	return response, nil
+}
@@ -27,6 +27,10 @@ func init() {
 			config.AppFlags.FlagString("influx.user", "", "User")
 			config.AppFlags.FlagString("influx.password", "", "Password")
 			config.AppFlags.FlagDuration("influx.timeout", 0, "Communication timeout")
+			config.AppFlags.FlagBool("influx.dryrun", false, "Log destructive operations instead of executing them")
+			config.AppFlags.FlagUint("influx.writerate", 0, "Maximum points written per second")
+			config.AppFlags.FlagString("influx.writerp", "", "Retention policy for writes")
+			config.AppFlags.FlagString("influx.consistency", "", "Write consistency (any, one, quorum, all)")
 		},
 		New: func(app *gopi.AppInstance) (gopi.Driver, error) {
 			host, _ := app.AppFlags.GetString("influx.host")
@@ -36,14 +40,22 @@ func init() {
 			user, _ := app.AppFlags.GetString("influx.user")
 			password, _ := app.AppFlags.GetString("influx.password")
 			timeout, _ := app.AppFlags.GetDuration("influx.timeout")
+			dryrun, _ := app.AppFlags.GetBool("influx.dryrun")
+			writerate, _ := app.AppFlags.GetUint("influx.writerate")
+			writerp, _ := app.AppFlags.GetString("influx.writerp")
+			consistency, _ := app.AppFlags.GetString("influx.consistency")
 			return gopi.Open(Config{
-				Host:      host,
-				Port:      port,
-				SSL:       ssl,
-				SSLVerify: sslverify,
-				Username:  user,
-				Password:  password,
-				Timeout:   timeout,
+				Host:                 host,
+				Port:                 port,
+				SSL:                  ssl,
+				SSLVerify:            sslverify,
+				Username:             user,
+				Password:             password,
+				Timeout:              timeout,
+				DryRun:               dryrun,
+				WriteRateLimit:       int(writerate),
+				WriteRetentionPolicy: writerp,
+				WriteConsistency:     consistency,
 			}, app.Logger)
 		},
 	})
@@ -0,0 +1,117 @@
+/*
+	InfluxDB client
+	(c) Copyright David Thorpe 2017
+	All Rights Reserved
+
+	For Licensing and Usage information, please see LICENSE file
+*/
+
+package v2
+
+import (
+	"net/http"
+	"sync"
+
+	gopi "github.com/djthorpe/gopi"
+	influxdb "github.com/djthorpe/influxdb"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// Pool hands out clients for the same server which share one transport,
+// so that connections and TLS handshakes are reused across clients
+type Pool struct {
+	sync.Mutex
+
+	config    Config
+	log       gopi.Logger
+	transport *http.Transport
+	idle      []*Client
+	out       map[*Client]bool
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// NEW AND CLOSE
+
+// NewPool returns a pool of clients for the configuration, which are
+// opened when first requested
+func NewPool(config Config, log gopi.Logger) *Pool {
+	return &Pool{
+		config:    config,
+		log:       log,
+		transport: newTransport(config),
+		out:       make(map[*Client]bool),
+	}
+}
+
+// Close closes the idle clients in the pool and the idle connections of
+// the shared transport. Clients which have not been returned to the pool
+// should be closed by the caller
+func (this *Pool) Close() error {
+	this.Lock()
+	defer this.Unlock()
+
+	var result error
+	for _, idle := range this.idle {
+		if err := idle.Close(); err != nil && result == nil {
+			result = err
+		}
+	}
+	this.idle = nil
+	this.transport.CloseIdleConnections()
+	return result
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// GET AND PUT
+
+// Get returns an idle client from the pool, or opens a new client if
+// there are no idle clients
+func (this *Pool) Get() (*Client, error) {
+	this.Lock()
+	if n := len(this.idle); n > 0 {
+		idle := this.idle[n-1]
+		this.idle = this.idle[:n-1]
+		this.out[idle] = true
+		this.Unlock()
+		return idle, nil
+	}
+	this.Unlock()
+	client, err := this.config.open(this.log, this.transport)
+	if err != nil {
+		return nil, err
+	}
+	this.Lock()
+	defer this.Unlock()
+	this.out[client] = true
+	return client, nil
+}
+
+// Put returns a client to the pool, resetting the current database,
+// precision and dry run to the pool configuration and removing any
+// cache. Returns ErrNotConnected for a closed client and
+// ErrBadParameter for a client which was not returned by Get, such as
+// a copy made with WithCache or WithDryRun, or which has already been
+// returned
+func (this *Pool) Put(idle *Client) error {
+	if idle == nil || idle.client == nil {
+		return influxdb.ErrNotConnected
+	}
+
+	this.Lock()
+	defer this.Unlock()
+	if this.out[idle] == false {
+		return influxdb.ErrBadParameter
+	}
+	delete(this.out, idle)
+	idle.database = this.config.Database
+	idle.precision = ""
+	if this.config.Precision != "" {
+		idle.SetPrecision(this.config.Precision)
+	}
+	idle.dryrun = this.config.DryRun
+	idle.cache = nil
+	this.idle = append(this.idle, idle)
+	return nil
+}
@@ -0,0 +1,81 @@
+/*
+	InfluxDB client
+	(c) Copyright David Thorpe 2017
+	All Rights Reserved
+
+	For Licensing and Usage information, please see LICENSE file
+*/
+
+package v2
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// tokenBucket limits the rate of points written. The bucket holds up to
+// one second of tokens, and a write of more points than are available
+// waits until enough tokens have accumulated
+type tokenBucket struct {
+	sync.Mutex
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTRUCTOR
+
+// newTokenBucket returns a full bucket which allows rate points per second
+func newTokenBucket(rate int) *tokenBucket {
+	return &tokenBucket{
+		rate:   float64(rate),
+		tokens: float64(rate),
+		last:   time.Now(),
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// wait blocks until n points can be written, or returns the context error
+// if the context is done first, in which case the tokens are returned
+func (this *tokenBucket) wait(ctx context.Context, n int) error {
+	delay := this.reserve(float64(n))
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		this.Lock()
+		this.tokens += float64(n)
+		this.Unlock()
+		return ctx.Err()
+	}
+}
+
+// reserve takes n tokens from the bucket and returns the time to wait
+// until the tokens would have been available
+func (this *tokenBucket) reserve(n float64) time.Duration {
+	this.Lock()
+	defer this.Unlock()
+	now := time.Now()
+	this.tokens += now.Sub(this.last).Seconds() * this.rate
+	if this.tokens > this.rate {
+		this.tokens = this.rate
+	}
+	this.last = now
+	this.tokens -= n
+	if this.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-this.tokens / this.rate * float64(time.Second))
+}
@@ -0,0 +1,414 @@
+/*
+	InfluxDB client
+	(c) Copyright David Thorpe 2017
+	All Rights Reserved
+
+	For Licensing and Usage information, please see LICENSE file
+*/
+
+package v2
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	influxdb "github.com/djthorpe/influxdb"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// MEASUREMENTS
+
+// GetMeasurements returns the names of the measurements in a database, or
+// in the current database when the database is empty
+func (this *Client) GetMeasurements(database string) ([]string, error) {
+	if this.client == nil {
+		return nil, influxdb.ErrNotConnected
+	}
+	results, err := this.Do(influxdb.ShowMeasurements().Database(database))
+	if err == influxdb.ErrEmptyResponse {
+		return []string{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	values, err := results.Column(0, "measurements", "name")
+	if err != nil {
+		return nil, err
+	}
+	measurements := make([]string, 0, len(values))
+	for _, value := range values {
+		if name, ok := value.(string); ok == false {
+			return nil, influxdb.ErrUnexpectedResponse
+		} else {
+			measurements = append(measurements, name)
+		}
+	}
+	return measurements, nil
+}
+
+// GetMeasurementsMatching returns the names of the measurements in the
+// current database which match a regular expression. Returns
+// ErrBadParameter if the pattern is not a valid regular expression
+func (this *Client) GetMeasurementsMatching(pattern string) ([]string, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", influxdb.ErrBadParameter, err)
+	}
+	measurements, err := this.GetMeasurements("")
+	if err != nil {
+		return nil, err
+	}
+	matches := make([]string, 0, len(measurements))
+	for _, name := range measurements {
+		if re.MatchString(name) {
+			matches = append(matches, name)
+		}
+	}
+	return matches, nil
+}
+
+// DropMeasurementsMatching drops the measurements in the current database
+// which match a regular expression, and returns the number of measurements
+// dropped. When dry run is enabled nothing is dropped, and a DryRunError
+// with the statements which would have been executed is returned
+func (this *Client) DropMeasurementsMatching(pattern string) (int, error) {
+	measurements, err := this.GetMeasurementsMatching(pattern)
+	if err != nil {
+		return 0, err
+	}
+	dropped := 0
+	statements := make([]string, 0, len(measurements))
+	for _, name := range measurements {
+		var dryrun *influxdb.DryRunError
+		if err := this.destroy(influxdb.DropMeasurement(name)); errors.As(err, &dryrun) {
+			statements = append(statements, dryrun.Statement)
+		} else if err != nil {
+			return dropped, err
+		} else {
+			dropped++
+		}
+	}
+	if len(statements) > 0 {
+		return 0, &influxdb.DryRunError{Statement: strings.Join(statements, "; ")}
+	}
+	return dropped, nil
+}
+
+// SeriesCardinalityExact returns the exact number of series in a
+// measurement, which may be qualified with a database, or in the current
+// database when the measurement is empty. This is precise but slower than
+// the estimated cardinality, since the series are counted from the index.
+// Requires InfluxDB 1.4 or later, and returns an error wrapping
+// ErrNotSupported otherwise or when the server version is not known
+func (this *Client) SeriesCardinalityExact(measurement string) (int, error) {
+	if this.client == nil {
+		return 0, influxdb.ErrNotConnected
+	} else if this.VersionAtLeast(1, 4) == false {
+		return 0, fmt.Errorf("%w: exact series cardinality requires InfluxDB 1.4", influxdb.ErrNotSupported)
+	}
+	q := influxdb.ShowSeriesCardinality(true)
+	if measurement != "" {
+		if m, err := influxdb.ParseMeasurement(measurement); err != nil {
+			return 0, err
+		} else {
+			q = q.Database(m.Database).Measurement(&influxdb.Measurement{Policy: m.Policy, Name: m.Name})
+		}
+	}
+	results, err := this.Do(q)
+	if err == influxdb.ErrEmptyResponse {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	cardinality := 0
+	for _, result := range results {
+		if i := result.ColumnIndex("count"); i < 0 {
+			return 0, influxdb.ErrUnexpectedResponse
+		} else {
+			for _, row := range result.Values {
+				if i >= len(row) {
+					return 0, influxdb.ErrUnexpectedResponse
+				} else if n, ok := row[i].(json.Number); ok == false {
+					return 0, influxdb.ErrUnexpectedResponse
+				} else if n, err := n.Int64(); err != nil {
+					return 0, influxdb.ErrUnexpectedResponse
+				} else {
+					cardinality += int(n)
+				}
+			}
+		}
+	}
+	return cardinality, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// FIELD KEYS
+
+// ShowFieldKeys returns the field keys for a measurement mapped to their
+// types. The measurement name may be qualified with a database and
+// retention policy. Returns an empty map for a measurement which does not
+// exist
+func (this *Client) ShowFieldKeys(measurement string) (map[string]string, error) {
+	if this.client == nil {
+		return nil, influxdb.ErrNotConnected
+	}
+	m, err := influxdb.ParseMeasurement(measurement)
+	if err != nil {
+		return nil, err
+	}
+	if results, err := this.Do(influxdb.ShowFieldKeys().Measurement(m)); err == influxdb.ErrEmptyResponse {
+		return map[string]string{}, nil
+	} else if err != nil {
+		return nil, err
+	} else if len(results) != 1 {
+		return nil, influxdb.ErrUnexpectedResponse
+	} else {
+		return results[0].ParseFieldKeys()
+	}
+}
+
+// ShowTagValues returns the values of tag keys for a measurement, grouped
+// by key, or the values of all tag keys when no keys are given. The
+// measurement name may be qualified with a database and retention policy.
+// Returns an empty map for a measurement which does not exist
+func (this *Client) ShowTagValues(measurement string, keys ...string) (map[string][]string, error) {
+	if this.client == nil {
+		return nil, influxdb.ErrNotConnected
+	}
+	m, err := influxdb.ParseMeasurement(measurement)
+	if err != nil {
+		return nil, err
+	}
+	if results, err := this.Do(influxdb.ShowTagValues(keys...).Measurement(m)); err == influxdb.ErrEmptyResponse {
+		return map[string][]string{}, nil
+	} else if err != nil {
+		return nil, err
+	} else if len(results) != 1 {
+		return nil, influxdb.ErrUnexpectedResponse
+	} else {
+		return results[0].ParseTagValues()
+	}
+}
+
+// GetMeasurementsWithTagKeys returns the measurements in the current
+// database mapped to their tag keys, using a single request. Measurements
+// without tags are mapped to an empty slice
+func (this *Client) GetMeasurementsWithTagKeys() (map[string][]string, error) {
+	if this.client == nil {
+		return nil, influxdb.ErrNotConnected
+	}
+	// Tag keys are only returned for measurements which have tags, so
+	// measurements are also requested in the same request
+	statement := influxdb.ShowMeasurements().String() + "; " + influxdb.ShowTagKeys().String()
+	response, err := this.query(statement)
+	if err != nil {
+		return nil, err
+	}
+	measurements := make(map[string][]string)
+	r, err := results(response)
+	if err == influxdb.ErrEmptyResponse {
+		return measurements, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if names, err := r.Column(0, "measurements", "name"); err == nil {
+		for _, name := range names {
+			if name, ok := name.(string); ok {
+				measurements[name] = []string{}
+			}
+		}
+	}
+	for _, result := range r {
+		if result.Result != 1 {
+			continue
+		} else if keys, err := result.ParseTagKeys(); err != nil {
+			return nil, err
+		} else {
+			measurements[result.Name] = keys
+		}
+	}
+	return measurements, nil
+}
+
+// MeasurementSummary returns the first and last timestamps, number of
+// points and series, and field keys of a measurement, using a single
+// request. The number of points is the largest count of any field.
+// Returns ErrNotFound for a measurement with no fields
+func (this *Client) MeasurementSummary(measurement string) (*influxdb.Summary, error) {
+	if this.client == nil {
+		return nil, influxdb.ErrNotConnected
+	}
+	m, err := influxdb.ParseMeasurement(measurement)
+	if err != nil {
+		return nil, err
+	}
+	statements := []string{
+		influxdb.ShowFieldKeys().Measurement(m).String(),
+		influxdb.ShowSeries().Measurement(m).String(),
+		"SELECT count(*) FROM " + m.String(),
+		influxdb.Select(m).OffsetLimit(0, 1).String(),
+		"SELECT * FROM " + m.String() + " ORDER BY time DESC LIMIT 1",
+	}
+	response, err := this.query(strings.Join(statements, "; "))
+	if err != nil {
+		return nil, err
+	}
+	r, err := results(response)
+	if err == influxdb.ErrEmptyResponse {
+		return nil, fmt.Errorf("%w: measurement %v", influxdb.ErrNotFound, m)
+	} else if err != nil {
+		return nil, err
+	}
+
+	summary := &influxdb.Summary{Measurement: m.Name}
+	for _, result := range r {
+		switch result.Result {
+		case 0:
+			if summary.Fields, err = result.ParseFieldKeys(); err != nil {
+				return nil, err
+			}
+		case 1:
+			summary.Series += uint64(len(result.Values))
+		case 2:
+			if summary.Points, err = pointCount(result); err != nil {
+				return nil, err
+			}
+		case 3, 4:
+			if len(result.Values) != 1 {
+				return nil, influxdb.ErrUnexpectedResponse
+			} else if ts, err := rowTime(result, result.Values[0]); err != nil {
+				return nil, err
+			} else if result.Result == 3 {
+				summary.First = ts
+			} else {
+				summary.Last = ts
+			}
+		}
+	}
+	if len(summary.Fields) == 0 {
+		return nil, fmt.Errorf("%w: measurement %v", influxdb.ErrNotFound, m)
+	}
+	return summary, nil
+}
+
+// DropAllSeriesInMeasurement drops every series in a measurement, which
+// may be qualified with a database and retention policy. Because this
+// removes all data in the measurement, confirm must be
+// ConfirmDropAllSeries or ErrConfirmationRequired is returned
+func (this *Client) DropAllSeriesInMeasurement(measurement string, confirm influxdb.Confirmation) error {
+	if this.client == nil {
+		return influxdb.ErrNotConnected
+	} else if confirm != influxdb.ConfirmDropAllSeries {
+		return influxdb.ErrConfirmationRequired
+	}
+	m, err := influxdb.ParseMeasurement(measurement)
+	if err != nil {
+		return err
+	}
+	return this.destroy(influxdb.DropSeries(m))
+}
+
+// CheckQuery returns warnings for a SELECT query which compares tags of
+// the measurements selected from with numbers or booleans, which never
+// match since tag values are strings. The tag keys are read with SHOW TAG
+// KEYS
+func (this *Client) CheckQuery(query influxdb.Query) ([]string, error) {
+	if this.client == nil {
+		return nil, influxdb.ErrNotConnected
+	}
+	return influxdb.CheckTagComparisons(query, func(m *influxdb.Measurement) ([]string, error) {
+		if results, err := this.Do(influxdb.ShowTagKeys().Measurement(m)); err == influxdb.ErrEmptyResponse {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		} else if len(results) != 1 {
+			return nil, influxdb.ErrUnexpectedResponse
+		} else {
+			return results[0].ParseTagKeys()
+		}
+	})
+}
+
+// ValidatePoint checks the field types of a point against the existing
+// field types of its measurement, and returns ErrFieldTypeConflict for
+// any field which would be rejected by the server. Fields which do not
+// yet exist are not checked. When SchemaCacheTTL is set, the field types
+// are cached, so fields created within the ttl may not be checked
+func (this *Client) ValidatePoint(point *influxdb.Point) error {
+	if point == nil {
+		return influxdb.ErrBadParameter
+	}
+	fields, err := this.fieldTypes(point.Measurement)
+	if err != nil {
+		return err
+	}
+
+	// Check fields in a stable order
+	keys := make([]string, 0, len(point.Fields))
+	for key := range point.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var result error
+	for _, key := range keys {
+		value := point.Fields[key]
+		if field_type := influxdb.FieldType(value); field_type == "" {
+			result = errors.Join(result, fmt.Errorf("%w: field %q has unsupported type %T", influxdb.ErrBadParameter, key, value))
+		} else if existing, exists := fields[key]; exists && existing != field_type {
+			result = errors.Join(result, fmt.Errorf("%w: field %q is %v, existing field is %v", influxdb.ErrFieldTypeConflict, key, field_type, existing))
+		}
+	}
+	return result
+}
+
+// RefreshSchema removes the cached field types for a measurement, or for
+// all measurements when the measurement is empty, so that they are read
+// from the server when next used
+func (this *Client) RefreshSchema(measurement string) {
+	if this.schema != nil {
+		this.schema.invalidate(this.database, measurement)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// fieldTypes returns the field types for a measurement, from the schema
+// cache when it is enabled
+func (this *Client) fieldTypes(measurement string) (map[string]string, error) {
+	if this.schema == nil {
+		return this.ShowFieldKeys(measurement)
+	} else if fields := this.schema.get(this.database, measurement); fields != nil {
+		return fields, nil
+	} else if fields, err := this.ShowFieldKeys(measurement); err != nil {
+		return nil, err
+	} else {
+		this.schema.set(this.database, measurement, fields)
+		return fields, nil
+	}
+}
+
+// pointCount returns the number of points from the result of a count(*)
+// query, which is the largest count of any field
+func pointCount(result *influxdb.Result) (uint64, error) {
+	var points uint64
+	for _, row := range result.Values {
+		for i, value := range row {
+			if i < len(result.Columns) && strings.HasPrefix(result.Columns[i], "count_") {
+				if n, ok := value.(json.Number); ok == false {
+					return 0, influxdb.ErrUnexpectedResponse
+				} else if n, err := strconv.ParseUint(n.String(), 10, 64); err != nil {
+					return 0, influxdb.ErrUnexpectedResponse
+				} else if n > points {
+					points = n
+				}
+			}
+		}
+	}
+	return points, nil
+}
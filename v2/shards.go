@@ -0,0 +1,168 @@
+/*
+	InfluxDB client
+	(c) Copyright David Thorpe 2017
+	All Rights Reserved
+
+	For Licensing and Usage information, please see LICENSE file
+*/
+
+package v2
+
+import (
+	"encoding/json"
+	"fmt"
+
+	influxdb "github.com/djthorpe/influxdb"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// SHARDS
+
+// ShowShards returns the shards on the server, keyed by database
+func (this *Client) ShowShards() (map[string][]*influxdb.Shard, error) {
+	if this.client == nil {
+		return nil, influxdb.ErrNotConnected
+	}
+	results, err := this.Do(influxdb.ShowShards())
+	if err != nil {
+		return nil, err
+	}
+	shards := make(map[string][]*influxdb.Shard, len(results))
+	for _, result := range results {
+		if s, err := result.ParseShards(); err != nil {
+			return nil, err
+		} else {
+			shards[result.Name] = append(shards[result.Name], s...)
+		}
+	}
+	return shards, nil
+}
+
+// ShowShardGroups returns the shard groups on the server
+func (this *Client) ShowShardGroups() ([]*influxdb.ShardGroup, error) {
+	if this.client == nil {
+		return nil, influxdb.ErrNotConnected
+	}
+	if results, err := this.Do(influxdb.ShowShardGroups()); err != nil {
+		return nil, err
+	} else if len(results) != 1 {
+		return nil, influxdb.ErrUnexpectedResponse
+	} else {
+		return results[0].ParseShardGroups()
+	}
+}
+
+// DropShard drops a shard and all the data it contains, for example to
+// remove a shard returned by ShowShards which has passed its expiry.
+// Returns ErrBadParameter if the id is not positive. When dry run is
+// enabled the shard is not dropped and a DryRunError is returned
+func (this *Client) DropShard(id int) error {
+	if this.client == nil {
+		return influxdb.ErrNotConnected
+	} else if id <= 0 {
+		return influxdb.ErrBadParameter
+	}
+	return this.destroy(influxdb.DropShard(uint64(id)))
+}
+
+// EstimatePointCount returns an estimate of the number of points in a
+// measurement, which may be qualified with a database. When the
+// measurement is the only measurement in its database, the points written
+// to the shards of the database are summed from the server statistics,
+// which avoids scanning the measurement. These statistics are reset when
+// the server restarts and include points which have since expired or been
+// deleted, so the estimate is approximate. Otherwise the points are
+// counted with SELECT count(*), which is exact but may be slow
+func (this *Client) EstimatePointCount(measurement string) (int64, error) {
+	if this.client == nil {
+		return 0, influxdb.ErrNotConnected
+	}
+	m, err := influxdb.ParseMeasurement(measurement)
+	if err != nil {
+		return 0, err
+	}
+	database := m.Database
+	if database == "" {
+		database = this.database
+	}
+	if points, ok, err := this.shardPoints(database, m.Name); err != nil {
+		this.log.Debug("EstimatePointCount: %v: %v", m, err)
+	} else if ok {
+		return points, nil
+	}
+
+	// Fall back to counting the points
+	if results, err := this.Do(influxdb.Select(m).Columns(influxdb.Count("*"))); err == influxdb.ErrEmptyResponse {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	} else if len(results) != 1 {
+		return 0, influxdb.ErrUnexpectedResponse
+	} else if points, err := pointCount(results[0]); err != nil {
+		return 0, err
+	} else {
+		return int64(points), nil
+	}
+}
+
+// CreateSnapshot triggers a backup of a database, or of the current
+// database when the database is empty. No version of InfluxDB 1.x
+// supports this over the HTTP API: the open source edition is backed up
+// with "influxd backup", which uses the RPC service on port 8088 rather
+// than HTTP, and InfluxDB Enterprise with "influxd-ctl backup" against the
+// meta nodes. Returns an error wrapping ErrNotSupported for every server
+// version, so that callers can fall back to those tools
+func (this *Client) CreateSnapshot(db string) error {
+	if this.client == nil {
+		return influxdb.ErrNotConnected
+	}
+	if db == "" {
+		db = this.database
+	}
+	if db == "" {
+		return influxdb.ErrBadParameter
+	}
+	return fmt.Errorf("%w: InfluxDB %v cannot back up %v over HTTP, use influxd backup", influxdb.ErrNotSupported, this.Version(), db)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// shardPoints returns the number of points written to the shards of a
+// database from the server statistics, and true if the measurement is the
+// only measurement in the database and shard statistics are available
+func (this *Client) shardPoints(database, measurement string) (int64, bool, error) {
+	if measurements, err := this.GetMeasurements(database); err != nil {
+		return 0, false, err
+	} else if len(measurements) != 1 || measurements[0] != measurement {
+		return 0, false, nil
+	}
+	results, err := this.Do(influxdb.ShowStats("shard"))
+	if err == influxdb.ErrEmptyResponse {
+		return 0, false, nil
+	} else if err != nil {
+		return 0, false, err
+	}
+	var points int64
+	found := false
+	for _, result := range results {
+		if result.Tags["database"] != database {
+			continue
+		}
+		for _, row := range result.Values {
+			for i, value := range row {
+				if i < len(result.Columns) && result.Columns[i] == "writePointsOk" {
+					if n, ok := value.(json.Number); ok == false {
+						return 0, false, influxdb.ErrUnexpectedResponse
+					} else if n, err := n.Int64(); err != nil {
+						return 0, false, influxdb.ErrUnexpectedResponse
+					} else {
+						points += n
+						found = true
+					}
+				}
+			}
+		}
+	}
+	return points, found, nil
+}
@@ -0,0 +1,112 @@
+/*
+	InfluxDB client
+	(c) Copyright David Thorpe 2017
+	All Rights Reserved
+
+	For Licensing and Usage information, please see LICENSE file
+*/
+
+package v2
+
+import (
+	"sync"
+	"time"
+
+	influxdb "github.com/djthorpe/influxdb"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TAIL
+
+// Tail polls a measurement on an interval for points newer than the last
+// point seen, starting from now, and calls fn with each set of new rows.
+// Errors from the server are logged and the poll is retried on the next
+// interval, so that polling continues when the server is unavailable.
+// Polling stops when fn returns an error or the returned stop function
+// is called, which waits for any callback in progress to return
+func (this *Client) Tail(measurement string, interval time.Duration, fn func(*influxdb.Result) error) (func(), error) {
+	if this.client == nil {
+		return nil, influxdb.ErrNotConnected
+	} else if interval <= 0 || fn == nil {
+		return nil, influxdb.ErrBadParameter
+	}
+	m, err := influxdb.ParseMeasurement(measurement)
+	if err != nil {
+		return nil, err
+	}
+
+	done := make(chan struct{})
+	var once sync.Once
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		watermark := time.Now()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if next, stop, err := this.tail(m, watermark, fn); err != nil {
+					this.log.Warn("Tail: %v: %v", m, err)
+				} else if stop {
+					return
+				} else {
+					watermark = next
+				}
+			}
+		}
+	}()
+
+	// Return the stop function
+	return func() {
+		once.Do(func() { close(done) })
+		wg.Wait()
+	}, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// tail queries for points after the watermark and calls fn with any new
+// rows, returning the new watermark. Returns true when fn returns an
+// error, to stop polling
+func (this *Client) tail(m *influxdb.Measurement, watermark time.Time, fn func(*influxdb.Result) error) (time.Time, bool, error) {
+	q := influxdb.Select(m).Where(influxdb.GreaterThan("time", watermark))
+	r, err := this.Do(q)
+	if err == influxdb.ErrEmptyResponse {
+		return watermark, false, nil
+	} else if err != nil {
+		return watermark, false, err
+	}
+	next := watermark
+	for _, result := range r {
+		if ts, err := lastTime(result); err != nil {
+			return watermark, false, err
+		} else if ts.After(next) {
+			next = ts
+		}
+	}
+	for _, result := range r {
+		if err := fn(result); err != nil {
+			this.log.Debug("Tail: %v: stopped: %v", m, err)
+			return watermark, true, nil
+		}
+	}
+	return next, false, nil
+}
+
+// lastTime returns the latest value in the time column of a result
+func lastTime(result *influxdb.Result) (time.Time, error) {
+	var last time.Time
+	for _, row := range result.Values {
+		if ts, err := rowTime(result, row); err != nil {
+			return time.Time{}, err
+		} else if ts.After(last) {
+			last = ts
+		}
+	}
+	return last, nil
+}
@@ -0,0 +1,133 @@
+/*
+	InfluxDB client
+	(c) Copyright David Thorpe 2017
+	All Rights Reserved
+
+	For Licensing and Usage information, please see LICENSE file
+*/
+
+package v2
+
+import (
+	"fmt"
+	"strings"
+
+	influxdb "github.com/djthorpe/influxdb"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// USERS
+
+// BootstrapAdmin creates the first admin user on a server, for
+// provisioning a fresh install. When authentication is enabled and there
+// are no users, the server rejects every statement except creating an
+// admin user, which is tolerated. Returns an error wrapping
+// ErrAlreadyExists if an admin user already exists. The password is not
+// logged
+func (this *Client) BootstrapAdmin(user, password string) error {
+	if this.client == nil {
+		return influxdb.ErrNotConnected
+	} else if user == "" || password == "" {
+		return influxdb.ErrBadParameter
+	}
+
+	// Check for existing admin users
+	if results, err := this.Do(influxdb.ShowUsers()); err == nil && len(results) == 1 {
+		if users, err := results[0].ParseUsers(); err != nil {
+			return err
+		} else {
+			for name, admin := range users {
+				if admin {
+					return fmt.Errorf("%w: admin user %q", influxdb.ErrAlreadyExists, name)
+				}
+			}
+		}
+	} else if err != nil && err != influxdb.ErrEmptyResponse && isBootstrapError(err) == false {
+		return err
+	}
+
+	// Create the admin user
+	if _, err := this.Do(influxdb.CreateUser(user, password, true)); err != nil && err != influxdb.ErrEmptyResponse {
+		if strings.Contains(strings.ToLower(err.Error()), "already exists") {
+			return fmt.Errorf("%w: %v", influxdb.ErrAlreadyExists, err)
+		}
+		return err
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// GRANTS
+
+// ShowGrants returns the database privileges granted to a user. Admin
+// users have all privileges on all databases, which is returned as a
+// first grant of "ALL PRIVILEGES" with an empty database
+func (this *Client) ShowGrants(user string) ([]influxdb.Grant, error) {
+	if this.client == nil {
+		return nil, influxdb.ErrNotConnected
+	} else if user == "" {
+		return nil, influxdb.ErrBadParameter
+	}
+	// Users are requested in the same request to determine if the user
+	// is an admin user, for whom grants are not returned
+	statement := influxdb.ShowUsers().String() + "; " + influxdb.ShowGrants(user).String()
+	response, err := this.query(statement)
+	if err != nil {
+		return nil, err
+	}
+	r, err := results(response)
+	if err == influxdb.ErrEmptyResponse {
+		return []influxdb.Grant{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	grants := make([]influxdb.Grant, 0, 1)
+	for _, result := range r {
+		if result.Result == 0 {
+			if users, err := result.ParseUsers(); err != nil {
+				return nil, err
+			} else if users[user] {
+				grants = append([]influxdb.Grant{{Privilege: "ALL PRIVILEGES"}}, grants...)
+			}
+		} else if g, err := result.ParseGrants(); err != nil {
+			return nil, err
+		} else {
+			grants = append(grants, g...)
+		}
+	}
+	return grants, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// SUBSCRIPTIONS
+
+// ShowSubscriptions returns the subscriptions for all databases
+func (this *Client) ShowSubscriptions() ([]influxdb.Subscription, error) {
+	if this.client == nil {
+		return nil, influxdb.ErrNotConnected
+	}
+	r, err := this.Do(influxdb.ShowSubscriptions())
+	if err == influxdb.ErrEmptyResponse {
+		return []influxdb.Subscription{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	subscriptions := make([]influxdb.Subscription, 0, len(r))
+	for _, result := range r {
+		if s, err := result.ParseSubscriptions(); err != nil {
+			return nil, err
+		} else {
+			subscriptions = append(subscriptions, s...)
+		}
+	}
+	return subscriptions, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// isBootstrapError returns true if an error is the server rejecting a
+// statement because no admin user has been created
+func isBootstrapError(err error) bool {
+	return strings.Contains(strings.ToLower(err.Error()), "create admin user first")
+}
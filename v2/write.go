@@ -0,0 +1,314 @@
+/*
+	InfluxDB client
+	(c) Copyright David Thorpe 2017
+	All Rights Reserved
+
+	For Licensing and Usage information, please see LICENSE file
+*/
+
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	influxdb "github.com/djthorpe/influxdb"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// target is the database and retention policy for a set of points
+type target struct {
+	database string
+	policy   string
+}
+
+// batch is a set of points in line protocol for a target, grouped by
+// measurement in the order in which the measurements first appear
+type batch struct {
+	target
+	lines        []string
+	measurements map[string][]string
+	order        []string
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// WRITE POINTS
+
+// WritePoint writes a single point
+func (this *Client) WritePoint(point *influxdb.Point) error {
+	return this.WriteBatch([]*influxdb.Point{point})
+}
+
+// WriteBatch writes a set of points. Points with a qualified measurement
+// name are written to the named retention policy and database, otherwise
+// the current database and the configured write retention policy (or the
+// default retention policy) are used. Points for each target are grouped
+// by measurement for write locality, keeping the order of points within
+// each measurement. Points are written with nanosecond timestamps, or
+// without a timestamp when the point time is zero so that the server
+// assigns one, and unsigned integer fields are only written to InfluxDB
+// 1.8 and later
+func (this *Client) WriteBatch(points []*influxdb.Point) error {
+	return this.WriteBatchContext(context.Background(), points)
+}
+
+// WriteVerified writes a point and then reads it back to confirm that it
+// has been stored, retrying until the point is visible or within elapses,
+// when an error wrapping ErrNotFound is returned. The point must have a
+// timestamp in Time or TimeUnixNano, so that it can be found. The read
+// back matches the timestamp and tags, not the field values
+func (this *Client) WriteVerified(point *influxdb.Point, within time.Duration) error {
+	if this.client == nil {
+		return influxdb.ErrNotConnected
+	} else if point == nil || (point.Time.IsZero() && point.TimeUnixNano == 0) || within <= 0 {
+		return influxdb.ErrBadParameter
+	}
+	m, err := influxdb.ParseMeasurement(point.Measurement)
+	if err != nil {
+		return err
+	}
+	if err := this.WritePoint(point); err != nil {
+		return err
+	}
+
+	// Read back the point by timestamp and tags
+	keys := make([]string, 0, len(point.Tags))
+	for key, value := range point.Tags {
+		if value != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	ts := point.Time
+	if point.TimeUnixNano != 0 {
+		ts = time.Unix(0, point.TimeUnixNano)
+	}
+	where := []influxdb.Predicate{influxdb.Equals("time", ts)}
+	for _, key := range keys {
+		where = append(where, influxdb.Equals(key, point.Tags[key]))
+	}
+	q := influxdb.Select(m).Filter(where...).OffsetLimit(0, 1)
+	deadline := time.Now().Add(within)
+	for {
+		if r, err := this.Do(q); err != nil && err != influxdb.ErrEmptyResponse {
+			return err
+		} else if len(r) > 0 && len(r[0].Values) > 0 {
+			return nil
+		} else if time.Now().Add(verifyInterval).After(deadline) {
+			return fmt.Errorf("%w: point not read back within %v", influxdb.ErrNotFound, within)
+		}
+		time.Sleep(verifyInterval)
+	}
+}
+
+// WriteBatchContext writes a set of points as WriteBatch does, cancelling
+// the write when the context is done. When a write rate limit is set,
+// blocks until the points can be written. On cancellation or timeout the
+// returned error wraps the context error
+func (this *Client) WriteBatchContext(ctx context.Context, points []*influxdb.Point) error {
+	if this.client == nil {
+		return influxdb.ErrNotConnected
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+	if this.limiter != nil {
+		if err := this.limiter.wait(ctx, len(points)); err != nil {
+			return fmt.Errorf("write: %w", err)
+		}
+	}
+	batches, err := this.batches(points)
+	if err != nil {
+		return err
+	}
+	for _, batch := range batches {
+		if err := this.metrics.write(this.write(ctx, batch)); err != nil {
+			return err
+		}
+		if this.cache != nil && this.settings.CacheInvalidateOnWrite {
+			for _, name := range batch.order {
+				this.cache.invalidate(batch.database, name)
+			}
+		}
+	}
+	return nil
+}
+
+// WriteJSONStream decodes a stream of JSON point objects, with the
+// fields of Point, and writes them in batches of WriteBatchSize points as
+// they are decoded, including the final partial batch. JSON numbers are
+// written as float fields. Cancellation is checked between points, and
+// returns an error wrapping the context error. Returns the number of
+// points written, which includes the points written before any error
+func (this *Client) WriteJSONStream(ctx context.Context, r io.Reader) (int, error) {
+	if this.client == nil {
+		return 0, influxdb.ErrNotConnected
+	} else if r == nil {
+		return 0, influxdb.ErrBadParameter
+	}
+	size := this.settings.WriteBatchSize
+	if size <= 0 {
+		size = defaultWriteBatchSize
+	}
+
+	decoder := json.NewDecoder(r)
+	points := make([]*influxdb.Point, 0, size)
+	written := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return written, fmt.Errorf("write: %w", err)
+		}
+		point := new(influxdb.Point)
+		if err := decoder.Decode(point); err == io.EOF {
+			break
+		} else if err != nil {
+			return written, fmt.Errorf("%w: point %v: %v", influxdb.ErrBadParameter, written+len(points)+1, err)
+		}
+		if points = append(points, point); len(points) >= size {
+			if err := this.WriteBatchContext(ctx, points); err != nil {
+				return written, err
+			}
+			written += len(points)
+			points = make([]*influxdb.Point, 0, size)
+		}
+	}
+	if len(points) > 0 {
+		if err := this.WriteBatchContext(ctx, points); err != nil {
+			return written, err
+		}
+		written += len(points)
+	}
+
+	// Return success
+	return written, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// batches returns the batches of points to write, one for each target, in
+// the order in which the targets first appear
+func (this *Client) batches(points []*influxdb.Point) ([]*batch, error) {
+	batches := make([]*batch, 0, 1)
+	targets := make(map[target]*batch)
+	encoder := influxdb.LineEncoder{Unsigned: this.VersionAtLeast(1, 8)}
+	for _, point := range points {
+		if point == nil {
+			return nil, influxdb.ErrBadParameter
+		}
+		measurement, err := influxdb.ParseMeasurement(point.Measurement)
+		if err != nil {
+			return nil, err
+		}
+		key := target{measurement.Database, measurement.Policy}
+		if key.database == "" && key.policy == "" {
+			key.policy = this.policy
+		}
+		if key.database == "" {
+			key.database = this.database
+		}
+		if key.database == "" {
+			return nil, influxdb.ErrBadParameter
+		}
+		b, exists := targets[key]
+		if exists == false {
+			b = &batch{target: key, measurements: make(map[string][]string)}
+			targets[key] = b
+			batches = append(batches, b)
+		}
+		pt := *point
+		pt.Measurement = measurement.Name
+		if line, err := encoder.Encode(&pt); err != nil {
+			return nil, err
+		} else {
+			b.add(measurement.Name, line)
+		}
+	}
+	for _, b := range batches {
+		for _, name := range b.order {
+			b.lines = append(b.lines, b.measurements[name]...)
+		}
+	}
+	return batches, nil
+}
+
+// add appends a line to the group for a measurement
+func (b *batch) add(name, line string) {
+	if _, exists := b.measurements[name]; exists == false {
+		b.order = append(b.order, name)
+	}
+	b.measurements[name] = append(b.measurements[name], line)
+}
+
+// write sends a batch of points to the server in line protocol
+func (this *Client) write(ctx context.Context, b *batch) error {
+	this.log.Debug("<influxdb.Write>{ database=%v policy=%v points=%v }", b.database, b.policy, len(b.lines))
+
+	values := url.Values{}
+	values.Set("db", b.database)
+	if b.policy != "" {
+		values.Set("rp", b.policy)
+	}
+	if this.consistency != "" {
+		values.Set("consistency", this.consistency)
+	}
+	req, err := http.NewRequest("POST", this.addr+"write?"+values.Encode(), strings.NewReader(strings.Join(b.lines, "\n")))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if this.config.Username != "" {
+		req.SetBasicAuth(this.config.Username, this.config.Password)
+	}
+	resp, err := this.http.Do(req.WithContext(ctx))
+	if err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("write: %w", ctx.Err())
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNoContent || resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	response := struct {
+		Error string `json:"error"`
+	}{}
+	json.NewDecoder(resp.Body).Decode(&response)
+	return writeError(resp, response.Error, len(b.lines))
+}
+
+// writeError returns the error for a failed write. Oversized writes
+// return an error wrapping ErrPayloadTooLarge, and writes to a read-only
+// server an error wrapping ErrReadOnly
+func writeError(resp *http.Response, message string, points int) error {
+	if resp.StatusCode == http.StatusRequestEntityTooLarge {
+		return fmt.Errorf("%w: %v points", influxdb.ErrPayloadTooLarge, points)
+	} else if isReadOnly(message) || (resp.StatusCode == http.StatusForbidden && message == "") {
+		if message == "" {
+			message = resp.Status
+		}
+		return fmt.Errorf("%w: %v", influxdb.ErrReadOnly, message)
+	} else if message == "" {
+		return fmt.Errorf("%w: %v", influxdb.ErrUnexpectedResponse, resp.Status)
+	} else {
+		return errors.New(message)
+	}
+}
+
+// isReadOnly returns true if an error message reports that the server
+// does not accept writes
+func isReadOnly(message string) bool {
+	message = strings.ToLower(message)
+	return strings.Contains(message, "read-only") || strings.Contains(message, "read only")
+}
@@ -0,0 +1,273 @@
+/*
+	InfluxDB client
+	(c) Copyright David Thorpe 2017
+	All Rights Reserved
+
+	For Licensing and Usage information, please see LICENSE file
+*/
+
+package influxdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// BufferedWriter accumulates points and writes them to a client in
+// batches, when the buffer is full or on a regular interval
+type BufferedWriter struct {
+	// Dedup when true collapses points with the same measurement, tags and
+	// timestamp on flush, so that only the last point written is sent
+	Dedup bool
+
+	// DrainTimeout when non-zero is the maximum time Close waits for the
+	// final flush to complete
+	DrainTimeout time.Duration
+
+	// MaxQueue when non-zero is the maximum number of points which are
+	// buffered or being written. When a write would exceed it, the
+	// QueuePolicy determines whether Write blocks or returns ErrQueueFull.
+	// It should be at least the buffer size
+	MaxQueue    int
+	QueuePolicy QueuePolicy
+
+	sync.Mutex
+	client  Client
+	size    int
+	points  []*Point
+	pending int
+	space   *sync.Cond
+	err     error
+	closed  bool
+	done    chan struct{}
+	once    sync.Once
+	wg      sync.WaitGroup
+	drain   sync.WaitGroup
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTRUCTOR
+
+// NewBufferedWriter returns a writer which writes points to the client
+// when size points have been buffered, and on every interval. When the
+// interval is zero, points are only written when the buffer is full or
+// on Flush and Close
+func NewBufferedWriter(client Client, size int, interval time.Duration) *BufferedWriter {
+	this := new(BufferedWriter)
+	this.client = client
+	this.size = size
+	this.points = make([]*Point, 0, size)
+	this.space = sync.NewCond(&this.Mutex)
+	this.done = make(chan struct{})
+	if interval > 0 {
+		this.wg.Add(1)
+		go this.background(interval)
+	}
+	return this
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Write adds points to the buffer, and flushes the buffer when full.
+// When MaxQueue is set and the queue is full, blocks until points have
+// been written or returns ErrQueueFull, depending on the QueuePolicy.
+// Returns ErrWriterClosed after Close has been called
+func (this *BufferedWriter) Write(points ...*Point) error {
+	this.Lock()
+	if this.MaxQueue > 0 && len(points) > this.MaxQueue {
+		this.Unlock()
+		return fmt.Errorf("%w: %v points exceeds the maximum queue size", ErrQueueFull, len(points))
+	}
+	for {
+		if this.closed {
+			this.Unlock()
+			return ErrWriterClosed
+		}
+		if this.MaxQueue <= 0 || len(this.points)+this.pending+len(points) <= this.MaxQueue {
+			break
+		}
+		if this.QueuePolicy == QueueReject {
+			this.Unlock()
+			return ErrQueueFull
+		}
+		if this.pending == 0 {
+			// Nothing is being written, so flush the buffer to make space
+			this.Unlock()
+			if err := this.Flush(); err != nil {
+				return err
+			}
+			this.Lock()
+		} else {
+			this.space.Wait()
+		}
+	}
+	this.points = append(this.points, points...)
+	full := len(this.points) >= this.size
+	this.Unlock()
+	if full {
+		return this.Flush()
+	}
+	return nil
+}
+
+// Flush writes any buffered points to the client. Returns the errors
+// from this flush and from any previous background flushes, joined
+func (this *BufferedWriter) Flush() error {
+	this.Lock()
+	points := this.points
+	this.points = make([]*Point, 0, this.size)
+	err := this.err
+	this.err = nil
+	this.pending += len(points)
+	this.Unlock()
+
+	// Release the space in the queue when the write completes
+	defer func(n int) {
+		this.Lock()
+		this.pending -= n
+		this.space.Broadcast()
+		this.Unlock()
+	}(len(points))
+
+	if this.Dedup {
+		points = dedup(points)
+	}
+	if len(points) > 0 {
+		if err_ := this.client.WriteBatch(points); err_ != nil {
+			err = joinErrors(err, err_)
+		}
+	}
+	return err
+}
+
+// Close stops the background flush and writes any buffered points,
+// blocking until the final flush completes. Returns the errors from the
+// final flush and from any background flushes since the last Flush,
+// joined with errors.Join so that every failure is reported. When
+// DrainTimeout is set and the flush does not complete in time, returns an
+// error wrapping context.DeadlineExceeded and the flush continues in the
+// background. Call Wait before closing the client to wait for it. It is
+// safe to call Close more than once
+func (this *BufferedWriter) Close() error {
+	this.Lock()
+	this.closed = true
+	this.space.Broadcast()
+	this.Unlock()
+	this.once.Do(func() { close(this.done) })
+	this.wg.Wait()
+	if this.DrainTimeout <= 0 {
+		this.drain.Wait()
+		return this.Flush()
+	}
+
+	// The flush returns its error to Close, or keeps it for Wait when
+	// Close has stopped waiting
+	result := make(chan error)
+	abandon := make(chan struct{})
+	this.drain.Add(1)
+	go func() {
+		defer this.drain.Done()
+		err := this.Flush()
+		select {
+		case result <- err:
+		case <-abandon:
+			if err != nil {
+				this.Lock()
+				this.err = joinErrors(this.err, err)
+				this.Unlock()
+			}
+		}
+	}()
+	timer := time.NewTimer(this.DrainTimeout)
+	defer timer.Stop()
+	select {
+	case err := <-result:
+		return err
+	case <-timer.C:
+		close(abandon)
+		return fmt.Errorf("close: %w", context.DeadlineExceeded)
+	}
+}
+
+// Wait blocks until any flush which continued after Close returned on
+// the DrainTimeout has completed, and returns its error. The client
+// should not be closed until Wait returns
+func (this *BufferedWriter) Wait() error {
+	this.drain.Wait()
+	this.Lock()
+	defer this.Unlock()
+	err := this.err
+	this.err = nil
+	return err
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// background flushes the buffer on an interval until the writer is closed
+func (this *BufferedWriter) background(interval time.Duration) {
+	defer this.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := this.Flush(); err != nil {
+				this.Lock()
+				this.err = joinErrors(this.err, err)
+				this.Unlock()
+			}
+		case <-this.done:
+			return
+		}
+	}
+}
+
+// joinErrors returns err when there is no previous error, so that a
+// single error is returned unwrapped, or otherwise both errors joined
+func joinErrors(previous, err error) error {
+	if previous == nil {
+		return err
+	}
+	return errors.Join(previous, err)
+}
+
+// dedup returns points with only the last point for each measurement, tag
+// set and timestamp, in the position of the first such point
+func dedup(points []*Point) []*Point {
+	index := make(map[string]int, len(points))
+	result := make([]*Point, 0, len(points))
+	for _, point := range points {
+		key := pointKey(point)
+		if i, exists := index[key]; exists {
+			result[i] = point
+		} else {
+			index[key] = len(result)
+			result = append(result, point)
+		}
+	}
+	return result
+}
+
+// pointKey returns the series key and timestamp for a point, escaped as
+// for the line protocol so that distinct tag sets have distinct keys. The
+// timestamp is TimeUnixNano when set, which is written instead of Time
+func pointKey(point *Point) string {
+	key := []string{escapeMeasurement(point.Measurement)}
+	for _, k := range sortedKeys(point.Tags) {
+		key = append(key, escapeTag(k)+"="+escapeTag(point.Tags[k]))
+	}
+	ts := point.Time
+	if point.TimeUnixNano != 0 {
+		ts = time.Unix(0, point.TimeUnixNano)
+	}
+	return strings.Join(key, ",") + " " + ts.UTC().Format(time.RFC3339Nano)
+}